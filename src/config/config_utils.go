@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+// Config is the optional config.yaml next to the .env file.
+type Config struct {
+	Origins []Origin `yaml:"origins"`
+	// Rates are the reimbursement rates by event date
+	Rates []Rate `yaml:"rates"`
+	// Employees sets the sheet order and colors; anyone not listed comes after
+	// in alphabetical order
+	Employees []Employee `yaml:"employees"`
+}
+
+// Origin is a kitchen that orders leave from.
+type Origin struct {
+	// Name is how the origin is shown in the report
+	Name string `yaml:"name"`
+	// Aliases are other ways the origin is written on cut sheets
+	Aliases []string `yaml:"aliases"`
+	// Address may reference environment variables, e.g. ${FREMONT_ADDRESS}
+	Address   string   `yaml:"address"`
+	Latitude  *float64 `yaml:"latitude"`
+	Longitude *float64 `yaml:"longitude"`
+	// TripModel is the default trip model for orders from this origin
+	TripModel string `yaml:"trip_model"`
+}
+
+// Load reads the config file at path, falling back to Default when it
+// doesn't exist. Without any rates, MILEAGE_RATE is used for every date.
+func Load(path string) (*Config, error) {
+	config, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Rates) == 0 {
+		config.Rates, err = envRates()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+func readFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	for i := range config.Origins {
+		config.Origins[i].Address = strings.TrimSpace(os.ExpandEnv(config.Origins[i].Address))
+	}
+	for i := range config.Employees {
+		config.Employees[i].expandEnv()
+	}
+
+	return &config, nil
+}
+
+// Default is the config used without a config file: the Fremont and Eastlake
+// kitchens with their addresses and trip models taken from the environment.
+// A kitchen whose address isn't set is left out.
+func Default() *Config {
+	config := &Config{}
+
+	for _, name := range []string{"Fremont", "Eastlake"} {
+		prefix := strings.ToUpper(name)
+		address := strings.TrimSpace(os.Getenv(prefix + "_ADDRESS"))
+		if address == "" {
+			continue
+		}
+
+		config.Origins = append(config.Origins, Origin{
+			Name:      name,
+			Address:   address,
+			TripModel: os.Getenv(prefix + "_TRIP_MODEL"),
+		})
+	}
+
+	return config
+}
+
+// A single MILEAGE_RATE covering every date, if it's set
+func envRates() ([]Rate, error) {
+	rateText := os.Getenv("MILEAGE_RATE")
+	if rateText == "" {
+		return nil, nil
+	}
+
+	perMile, err := strconv.ParseFloat(rateText, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MILEAGE_RATE: %s", rateText)
+	}
+
+	return []Rate{{PerMile: perMile}}, nil
+}
+
+// Validate checks that every origin can be told apart and has an address.
+func (c *Config) Validate() error {
+	seen := make(map[string]string)
+
+	for _, origin := range c.Origins {
+		if origin.Name == "" {
+			return errors.New("every origin needs a name")
+		}
+
+		if origin.Address == "" {
+			return fmt.Errorf("origin %s has no address", origin.Name)
+		}
+
+		if (origin.Latitude == nil) != (origin.Longitude == nil) {
+			return fmt.Errorf("origin %s needs both a latitude and a longitude", origin.Name)
+		}
+
+		if origin.TripModel != "" {
+			if _, err := travel.ParseTripModel(origin.TripModel); err != nil {
+				return fmt.Errorf("origin %s: %w", origin.Name, err)
+			}
+		}
+
+		for _, name := range origin.names() {
+			key := strings.ToLower(name)
+			if other, ok := seen[key]; ok {
+				return fmt.Errorf("%q is used by both %s and %s", name, other, origin.Name)
+			}
+			seen[key] = origin.Name
+		}
+	}
+
+	for _, employee := range c.Employees {
+		if err := c.validateEmployee(employee); err != nil {
+			return err
+		}
+	}
+
+	return validateRates(c.Rates)
+}
+
+// FindOrigin looks an origin up by its name or one of its aliases.
+func (c *Config) FindOrigin(name string) *Origin {
+	for i, origin := range c.Origins {
+		for _, candidate := range origin.names() {
+			if strings.EqualFold(candidate, strings.TrimSpace(name)) {
+				return &c.Origins[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// RoutingAddress is what's sent to the distance provider: the coordinates
+// when they're known, otherwise the street address.
+func (o Origin) RoutingAddress() string {
+	if o.Latitude != nil && o.Longitude != nil {
+		return fmt.Sprintf("%f,%f", *o.Latitude, *o.Longitude)
+	}
+	return o.Address
+}
+
+func (o Origin) names() []string {
+	return append([]string{o.Name}, o.Aliases...)
+}
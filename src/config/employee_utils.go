@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+// EmployeeFileName is the optional settings file inside an employee's folder.
+const EmployeeFileName = "employee.yaml"
+
+var colorRe = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Employee holds settings for one employee folder.
+type Employee struct {
+	Name string `yaml:"name"`
+	// Color of the employee's sheet header, e.g. "#FF7F00"
+	Color string `yaml:"color"`
+	// Origin replaces the cut sheet's origin with another configured one
+	Origin string `yaml:"origin"`
+	// Home replaces the cut sheet's origin for drivers who start from home
+	Home *Origin `yaml:"home"`
+	// TripModel is the default trip model for the employee's orders
+	TripModel string `yaml:"trip_model"`
+	// DailyMilesCap limits the miles reimbursed per day, when set
+	DailyMilesCap float64 `yaml:"daily_miles_cap"`
+
+	// Only in the employee's folder, not the roster, so it doesn't set the sheet order
+	fromFolder bool
+}
+
+// FindEmployee looks an employee up by folder name, ignoring case.
+func (c *Config) FindEmployee(name string) *Employee {
+	for i, employee := range c.Employees {
+		if strings.EqualFold(employee.Name, name) {
+			return &c.Employees[i]
+		}
+	}
+
+	return nil
+}
+
+// Listed reports whether the employee is in the config file's roster, as
+// opposed to only having a file in their folder.
+func (e Employee) Listed() bool {
+	return !e.fromFolder
+}
+
+// LoadEmployeeFile reads employee.yaml from an employee's folder, if there is
+// one. Its settings win over the employee's entry in the roster.
+func (c *Config) LoadEmployeeFile(name, folder string) error {
+	path := filepath.Join(folder, EmployeeFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var settings Employee
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("error reading %s: %w", path, err)
+	}
+	settings.expandEnv()
+
+	employee := c.FindEmployee(name)
+	if employee == nil {
+		c.Employees = append(c.Employees, Employee{Name: name, fromFolder: true})
+		employee = &c.Employees[len(c.Employees)-1]
+	}
+
+	if settings.Color != "" {
+		employee.Color = settings.Color
+	}
+	if settings.Origin != "" || settings.Home != nil {
+		employee.Origin = settings.Origin
+		employee.Home = settings.Home
+	}
+	if settings.TripModel != "" {
+		employee.TripModel = settings.TripModel
+	}
+	if settings.DailyMilesCap != 0 {
+		employee.DailyMilesCap = settings.DailyMilesCap
+	}
+
+	return nil
+}
+
+// OriginOverride is the origin the employee's orders leave from instead of
+// the one on the cut sheet, or nil to use the cut sheet's.
+func (c *Config) OriginOverride(employee *Employee) *Origin {
+	if employee == nil {
+		return nil
+	}
+	if employee.Home != nil {
+		return employee.Home
+	}
+	if employee.Origin != "" {
+		return c.FindOrigin(employee.Origin)
+	}
+	return nil
+}
+
+func (e *Employee) expandEnv() {
+	if e.Home != nil {
+		e.Home.Address = strings.TrimSpace(os.ExpandEnv(e.Home.Address))
+		if e.Home.Name == "" {
+			e.Home.Name = "Home"
+		}
+	}
+}
+
+func (c *Config) validateEmployee(employee Employee) error {
+	if employee.Name == "" {
+		return errors.New("every employee needs a name")
+	}
+
+	if employee.Color != "" && !colorRe.MatchString(employee.Color) {
+		return fmt.Errorf("employee %s has an invalid color %q, please use #RRGGBB", employee.Name, employee.Color)
+	}
+
+	if employee.Origin != "" && employee.Home != nil {
+		return fmt.Errorf("employee %s can have an origin or a home, not both", employee.Name)
+	}
+
+	if employee.Origin != "" && c.FindOrigin(employee.Origin) == nil {
+		return fmt.Errorf("employee %s has an unknown origin: %s", employee.Name, employee.Origin)
+	}
+
+	if home := employee.Home; home != nil {
+		if home.Address == "" {
+			return fmt.Errorf("employee %s's home has no address", employee.Name)
+		}
+		if (home.Latitude == nil) != (home.Longitude == nil) {
+			return fmt.Errorf("employee %s's home needs both a latitude and a longitude", employee.Name)
+		}
+	}
+
+	if employee.TripModel != "" {
+		if _, err := travel.ParseTripModel(employee.TripModel); err != nil {
+			return fmt.Errorf("employee %s: %w", employee.Name, err)
+		}
+	}
+
+	if employee.DailyMilesCap < 0 {
+		return fmt.Errorf("employee %s has a negative daily_miles_cap", employee.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rate is the reimbursement per mile for events between From and To.
+type Rate struct {
+	From    Date    `yaml:"from"`
+	To      Date    `yaml:"to"`
+	PerMile float64 `yaml:"per_mile"`
+}
+
+// Date is a YYYY-MM-DD date in the config file. The zero Date leaves a rate
+// open ended.
+type Date struct {
+	time.Time
+}
+
+func (d *Date) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.Parse("2006-01-02", node.Value)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, please use YYYY-MM-DD", node.Value)
+	}
+	d.Time = parsed
+	return nil
+}
+
+// Covers reports whether the rate applies to an event on the date.
+func (r Rate) Covers(date time.Time) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if !r.From.IsZero() && day.Before(r.From.Time) {
+		return false
+	}
+	if !r.To.IsZero() && day.After(r.To.Time) {
+		return false
+	}
+	return true
+}
+
+// RateFor returns the per-mile rate for an event on the date. When several
+// rates cover it, the one that started most recently wins.
+func (c *Config) RateFor(date time.Time) (float64, bool) {
+	var best *Rate
+	for i, rate := range c.Rates {
+		if !rate.Covers(date) {
+			continue
+		}
+		if best == nil || rate.From.After(best.From.Time) {
+			best = &c.Rates[i]
+		}
+	}
+
+	if best == nil {
+		return 0, false
+	}
+	return best.PerMile, true
+}
+
+func validateRates(rates []Rate) error {
+	for _, rate := range rates {
+		if rate.PerMile < 0 {
+			return fmt.Errorf("rate from %s has a negative per_mile", rate.From.Format("2006-01-02"))
+		}
+		if !rate.From.IsZero() && !rate.To.IsZero() && rate.To.Before(rate.From.Time) {
+			return fmt.Errorf("rate from %s ends before it starts", rate.From.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package fileutils
+
+import (
+	"fmt"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+// Trims each employee's orders so no day goes over their daily miles cap.
+// Orders are counted in date and order ID order, so the last ones of the day
+// are the ones cut. Chained routes are trimmed to match.
+func applyDailyCaps(cfg *config.Config, employeeOrders map[string][]orderInfo, employeeRoutes map[string][]routeInfo) {
+	if cfg == nil {
+		return
+	}
+
+	for employee, orders := range employeeOrders {
+		settings := cfg.FindEmployee(employee)
+		if settings == nil || settings.DailyMilesCap <= 0 {
+			continue
+		}
+
+		dailyCap := travel.Round(travel.OutputUnit().FromMeters(travel.Miles.ToMeters(settings.DailyMilesCap)))
+		capNote := fmt.Sprintf("Capped at %g %s/day", dailyCap, travel.OutputUnit().Label)
+
+		used := make(map[string]float64)
+		for i := range orders {
+			order := &orders[i]
+			remaining := max(dailyCap-used[order.Date], 0)
+			if order.Mileage > remaining {
+				order.UncappedMileage = order.Mileage
+				order.Mileage = travel.Round(remaining)
+				order.Adjustments = append(order.Adjustments, capNote)
+			}
+			used[order.Date] += order.Mileage
+		}
+
+		// A route can't save more than what's left of its orders
+		byID := make(map[string]orderInfo)
+		for _, order := range orders {
+			byID[order.OrderID] = order
+		}
+		routes := employeeRoutes[employee]
+		for i := range routes {
+			naive := 0.0
+			for _, orderID := range routes[i].OrderIDs {
+				naive += byID[orderID].Mileage
+			}
+			routes[i].NaiveMileage = travel.Round(naive)
+			routes[i].ChainedMileage = min(routes[i].ChainedMileage, routes[i].NaiveMileage)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package fileutils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Column is an optional cut sheet field shown with each order.
+type Column struct {
+	Name   string
+	Header string
+	value  func(order orderInfo) string
+}
+
+var columns = []Column{
+	{Name: "client", Header: "Client", value: func(o orderInfo) string { return o.Client }},
+	{Name: "contact", Header: "Contact", value: func(o orderInfo) string { return o.Contact }},
+	{Name: "phone", Header: "Phone", value: func(o orderInfo) string { return o.Phone }},
+	{Name: "end-time", Header: "End Time", value: func(o orderInfo) string { return o.EndTime }},
+	{Name: "service", Header: "Service Type", value: func(o orderInfo) string { return o.ServiceType }},
+}
+
+// ParseColumns looks up optional columns by name: client, contact, phone,
+// end-time and service, or "all" for every one of them.
+func ParseColumns(names []string) ([]Column, error) {
+	var selected []Column
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+		if name == "all" {
+			return columns, nil
+		}
+
+		found := false
+		for _, column := range columns {
+			if column.Name == name {
+				if !seen[name] {
+					selected = append(selected, column)
+					seen[name] = true
+				}
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown report column: %s", name)
+		}
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,226 @@
+package fileutils
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+const duplicatesSheetName = "Duplicates"
+
+// duplicateInfo is a set of cut sheets for the same order, found by order ID
+// or by identical file contents.
+type duplicateInfo struct {
+	Reason string
+	Orders []duplicateOrder
+}
+
+type duplicateOrder struct {
+	Employee string
+	Order    orderInfo
+	// Left out of the totals; the first copy is always kept
+	Excluded bool
+}
+
+type orderRef struct {
+	employee string
+	index    int
+}
+
+// Groups orders that share an order ID or a file hash, across every employee.
+// Copies are listed in report order, so the first is the one that is kept.
+func findDuplicates(cfg *config.Config, employeeOrders map[string][]orderInfo) []duplicateInfo {
+	employees := make([]string, 0, len(employeeOrders))
+	for employee := range employeeOrders {
+		employees = append(employees, employee)
+	}
+
+	var refs []orderRef
+	for _, employee := range sortEmployees(cfg, employees) {
+		for i := range employeeOrders[employee] {
+			refs = append(refs, orderRef{employee: employee, index: i})
+		}
+	}
+
+	// Union the orders that share either key, so a renamed copy of a cut sheet
+	// and a second driver's copy of the same order end up in one group
+	parent := make([]int, len(refs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	firstByID := make(map[string]int)
+	firstByHash := make(map[string]int)
+	for i, ref := range refs {
+		order := employeeOrders[ref.employee][ref.index]
+		for _, key := range []struct {
+			value string
+			seen  map[string]int
+		}{{order.OrderID, firstByID}, {order.FileHash, firstByHash}} {
+			if key.value == "" {
+				continue
+			}
+			if first, ok := key.seen[key.value]; ok {
+				parent[find(i)] = find(first)
+			} else {
+				key.seen[key.value] = i
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range refs {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	var duplicates []duplicateInfo
+	for _, root := range roots {
+		members := groups[root]
+		if len(members) < 2 {
+			continue
+		}
+
+		duplicate := duplicateInfo{}
+		sameID, sameHash := true, true
+		first := employeeOrders[refs[members[0]].employee][refs[members[0]].index]
+		for _, member := range members {
+			ref := refs[member]
+			order := employeeOrders[ref.employee][ref.index]
+			sameID = sameID && order.OrderID == first.OrderID
+			sameHash = sameHash && order.FileHash == first.FileHash
+			duplicate.Orders = append(duplicate.Orders, duplicateOrder{Employee: ref.employee, Order: order})
+		}
+
+		switch {
+		case sameID && sameHash:
+			duplicate.Reason = "Same file and order ID"
+		case sameHash:
+			duplicate.Reason = "Same file"
+		case sameID:
+			duplicate.Reason = "Same order ID"
+		default:
+			duplicate.Reason = "Same order ID or file"
+		}
+
+		duplicates = append(duplicates, duplicate)
+	}
+
+	return duplicates
+}
+
+// Removes every copy but the first from the orders and marks them excluded.
+// Employees left without orders are dropped.
+func excludeDuplicates(employeeOrders map[string][]orderInfo, duplicates []duplicateInfo) {
+	excluded := make(map[string]map[string]bool)
+	for i := range duplicates {
+		for j := 1; j < len(duplicates[i].Orders); j++ {
+			duplicate := &duplicates[i].Orders[j]
+			duplicate.Excluded = true
+			if excluded[duplicate.Employee] == nil {
+				excluded[duplicate.Employee] = make(map[string]bool)
+			}
+			excluded[duplicate.Employee][duplicate.Order.Filename] = true
+		}
+	}
+
+	for employee, files := range excluded {
+		var kept []orderInfo
+		for _, order := range employeeOrders[employee] {
+			if !files[order.Filename] {
+				kept = append(kept, order)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(employeeOrders, employee)
+			continue
+		}
+		employeeOrders[employee] = kept
+	}
+}
+
+// Copies the adjusted orders, such as those cut by a daily cap, back into
+// their duplicates. Excluded copies were never counted and are left alone.
+func updateDuplicates(employeeOrders map[string][]orderInfo, duplicates []duplicateInfo) {
+	for i := range duplicates {
+		for j := range duplicates[i].Orders {
+			duplicate := &duplicates[i].Orders[j]
+			if duplicate.Excluded {
+				continue
+			}
+			for _, order := range employeeOrders[duplicate.Employee] {
+				if order.Filename == duplicate.Order.Filename {
+					duplicate.Order = order
+					break
+				}
+			}
+		}
+	}
+}
+
+// Lists each group of duplicates with every employee and file involved
+func writeDuplicatesSheet(f *excelize.File, duplicates []duplicateInfo) {
+	f.NewSheet(duplicatesSheetName)
+
+	f.SetCellValue(duplicatesSheetName, "A1", "Duplicates")
+	titleStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 14},
+	})
+	f.SetCellStyle(duplicatesSheetName, "A1", "A1", titleStyle)
+
+	distanceHeader := fmt.Sprintf("Distance (%s)", travel.OutputUnit().Label)
+	setRow(f, duplicatesSheetName, 2, []string{"Group", "Reason", "Employee", "File", "Order ID", "Date", distanceHeader, "Status"})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	f.SetCellStyle(duplicatesSheetName, "A2", "H2", headerStyle)
+
+	distanceFormat := distanceNumberFormat(travel.OutputPrecision())
+	distanceStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &distanceFormat})
+	dateStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+
+	row := 3
+	for i, duplicate := range duplicates {
+		for _, order := range duplicate.Orders {
+			status := "Counted"
+			if order.Excluded {
+				status = "Excluded"
+			}
+
+			setRow(f, duplicatesSheetName, row, []any{
+				i + 1,
+				duplicate.Reason,
+				order.Employee,
+				order.Order.Filename,
+				order.Order.OrderID,
+				excelDate(order.Order.Date),
+				order.Order.Mileage,
+				status,
+			})
+			row++
+		}
+	}
+
+	f.SetCellStyle(duplicatesSheetName, "F3", fmt.Sprintf("F%d", row-1), dateStyle)
+	f.SetCellStyle(duplicatesSheetName, "G3", fmt.Sprintf("G%d", row-1), distanceStyle)
+
+	f.SetColWidth(duplicatesSheetName, "A", "H", 15)
+	f.SetColWidth(duplicatesSheetName, "B", "B", 22)
+	f.SetColWidth(duplicatesSheetName, "D", "D", 30)
+}
@@ -0,0 +1,86 @@
+package fileutils
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
+)
+
+// Rainbow colors
+var employeeColors = []string{"#FF0000", "#FF7F00", "#FFFF00", "#00FF00", "#0000FF", "#8B00FF"}
+
+// Orders employees as listed in the config, then everyone else alphabetically
+func sortEmployees(cfg *config.Config, employees []string) []string {
+	sorted := append([]string(nil), employees...)
+
+	position := func(employee string) int {
+		if cfg != nil {
+			for i, configured := range cfg.Employees {
+				if configured.Listed() && strings.EqualFold(configured.Name, employee) {
+					return i
+				}
+			}
+		}
+		return math.MaxInt
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, pj := position(sorted[i]), position(sorted[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return strings.ToLower(sorted[i]) < strings.ToLower(sorted[j])
+	})
+
+	return sorted
+}
+
+// The employee's configured color, or one picked from a hash of their name so
+// it stays the same between runs
+func employeeColor(cfg *config.Config, employee string) string {
+	if cfg != nil {
+		if configured := cfg.FindEmployee(employee); configured != nil && configured.Color != "" {
+			return strings.ToUpper(configured.Color)
+		}
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.ToLower(employee)))
+	return employeeColors[hash.Sum32()%uint32(len(employeeColors))]
+}
+
+// Groups errors by employee in sheet order, keeping each employee's in file order
+func sortErrors(cfg *config.Config, failures []errorInfo) []errorInfo {
+	var employees []string
+	seen := make(map[string]bool)
+	for _, failure := range failures {
+		if !seen[failure.Employee] {
+			seen[failure.Employee] = true
+			employees = append(employees, failure.Employee)
+		}
+	}
+
+	var sorted []errorInfo
+	for _, employee := range sortEmployees(cfg, employees) {
+		for _, failure := range failures {
+			if failure.Employee == employee {
+				sorted = append(sorted, failure)
+			}
+		}
+	}
+
+	return sorted
+}
+
+// Sorts orders by date, then order ID
+func sortOrders(orders []orderInfo) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		if orders[i].Date != orders[j].Date {
+			return orders[i].Date < orders[j].Date
+		}
+		return orders[i].OrderID < orders[j].OrderID
+	})
+}
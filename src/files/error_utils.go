@@ -0,0 +1,37 @@
+package fileutils
+
+import (
+	"fmt"
+
+	"github.com/jlsnow301/cutsheet-traveller/header"
+)
+
+// ErrorKind is the reason a cut sheet could not be processed.
+type ErrorKind string
+
+const (
+	PDFUnreadable ErrorKind = "PDF unreadable"
+	NoDestination ErrorKind = "No destination"
+	UnknownOrigin ErrorKind = "Unknown origin"
+	BadTime       ErrorKind = "Bad time"
+	BadTripModel  ErrorKind = "Bad trip model"
+	RoutingFailed ErrorKind = "Routing failure"
+)
+
+// orderError carries why a cut sheet failed along with whatever of its
+// header could be parsed.
+type orderError struct {
+	Kind    ErrorKind
+	Message string
+	Header  header.HeaderInfo
+}
+
+func (e *orderError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+// Returns the problem as an orderError, printed once the cut sheet's results
+// are collected
+func newOrderError(kind ErrorKind, headerInfo header.HeaderInfo, format string, args ...any) *orderError {
+	return &orderError{Kind: kind, Message: fmt.Sprintf(format, args...), Header: headerInfo}
+}
@@ -0,0 +1,447 @@
+package fileutils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+// Exporter writes a report in one file format.
+type Exporter interface {
+	// Extension is the file extension the format is saved with, e.g. ".csv"
+	Extension() string
+	Export(report Report, outputPath string) error
+}
+
+type excelExporter struct{}
+
+func (excelExporter) Extension() string { return ".xlsx" }
+
+func (excelExporter) Export(report Report, outputPath string) error {
+	return CreateExcelFile(report, outputPath)
+}
+
+type csvExporter struct{}
+
+func (csvExporter) Extension() string { return ".csv" }
+
+func (csvExporter) Export(report Report, outputPath string) error {
+	return CreateCSVFile(report, outputPath)
+}
+
+type itemsExporter struct{}
+
+func (itemsExporter) Extension() string { return ".items.csv" }
+
+func (itemsExporter) Export(report Report, outputPath string) error {
+	return CreateItemsFile(report, outputPath)
+}
+
+type jsonExporter struct{}
+
+func (jsonExporter) Extension() string { return ".json" }
+
+func (jsonExporter) Export(report Report, outputPath string) error {
+	return CreateJSONFile(report, outputPath)
+}
+
+// NewExporter returns the exporter for "xlsx", "csv", "items" or "json".
+func NewExporter(format string) (Exporter, error) {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), ".")) {
+	case "xlsx", "excel":
+		return excelExporter{}, nil
+	case "csv":
+		return csvExporter{}, nil
+	case "items":
+		return itemsExporter{}, nil
+	case "json":
+		return jsonExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format: %s", format)
+	}
+}
+
+// ExportPath swaps the extension of the output path for the exporter's.
+func ExportPath(outputPath string, exporter Exporter) string {
+	base := strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
+	return strings.TrimSuffix(base, ".items") + exporter.Extension()
+}
+
+// CreateCSVFile writes one row per order, for payroll imports.
+func CreateCSVFile(report Report, outputPath string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	headers := []string{
+		"Employee",
+		"Order ID",
+		fmt.Sprintf("Distance (%s)", travel.OutputUnit().Label),
+		"Trip",
+		"Rate",
+		"Reimbursement",
+		"Date",
+		"Origin",
+		"Destination",
+		"Adjustments",
+		fmt.Sprintf("Uncapped Distance (%s)", travel.OutputUnit().Label),
+	}
+	for _, column := range report.Columns {
+		headers = append(headers, column.Header)
+	}
+	w.Write(headers)
+
+	employees := make([]string, 0, len(report.Orders))
+	for employee := range report.Orders {
+		employees = append(employees, employee)
+	}
+
+	precision := travel.OutputPrecision()
+	for _, employee := range sortEmployees(report.Config, employees) {
+		for _, order := range report.Orders[employee] {
+			rate, amount := "", ""
+			if perMile, ok := rateFor(report.Config, order.EventTime); ok {
+				rate = strconv.FormatFloat(perMile, 'f', -1, 64)
+				amount = strconv.FormatFloat(reimbursement(order.Mileage, perMile), 'f', 2, 64)
+			}
+
+			// Left blank unless the daily cap reduced the distance
+			uncapped := ""
+			if order.UncappedMileage > 0 {
+				uncapped = strconv.FormatFloat(order.UncappedMileage, 'f', precision, 64)
+			}
+
+			values := []string{
+				employee,
+				order.OrderID,
+				strconv.FormatFloat(order.Mileage, 'f', precision, 64),
+				string(order.TripModel),
+				rate,
+				amount,
+				order.Date,
+				order.Origin,
+				order.Destination,
+				strings.Join(order.Adjustments, "; "),
+				uncapped,
+			}
+			for _, column := range report.Columns {
+				values = append(values, column.value(order))
+			}
+			w.Write(values)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// CreateItemsFile writes one row per line item of every order.
+func CreateItemsFile(report Report, outputPath string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"Employee", "Order ID", "Date", "Quantity", "Item", "Notes", "Equipment", "Equipment heavy"})
+
+	employees := make([]string, 0, len(report.Orders))
+	for employee := range report.Orders {
+		employees = append(employees, employee)
+	}
+
+	for _, employee := range sortEmployees(report.Config, employees) {
+		for _, order := range report.Orders[employee] {
+			for _, item := range order.Items {
+				w.Write([]string{
+					employee,
+					order.OrderID,
+					order.Date,
+					strconv.FormatFloat(item.Quantity, 'f', -1, 64),
+					item.Name,
+					strings.Join(item.Notes, "; "),
+					strconv.FormatBool(item.IsEquipment()),
+					strconv.FormatBool(order.EquipmentHeavy),
+				})
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+type jsonReport struct {
+	Title          string          `json:"title"`
+	RunID          string          `json:"run_id,omitempty"`
+	Period         *jsonPeriod     `json:"period,omitempty"`
+	Unit           string          `json:"unit"`
+	Employees      []jsonEmployee  `json:"employees"`
+	PreviouslyPaid []jsonPaid      `json:"previously_paid"`
+	Duplicates     []jsonDuplicate `json:"duplicates"`
+	Errors         []jsonError     `json:"errors"`
+}
+
+type jsonPeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type jsonEmployee struct {
+	Name          string      `json:"name"`
+	Orders        []jsonOrder `json:"orders"`
+	Routes        []jsonRoute `json:"routes,omitempty"`
+	Distance      float64     `json:"distance"`
+	Reimbursement float64     `json:"reimbursement"`
+}
+
+type jsonOrder struct {
+	OrderID        string     `json:"order_id"`
+	Distance       float64    `json:"distance"`
+	TripModel      string     `json:"trip_model"`
+	Rate           *float64   `json:"rate"`
+	Reimbursement  *float64   `json:"reimbursement"`
+	Date           string     `json:"date"`
+	EventTime      string     `json:"event_time"`
+	Origin         string     `json:"origin"`
+	OriginAddress  string     `json:"origin_address"`
+	Destination    string     `json:"destination"`
+	File           string     `json:"file"`
+	Items          []jsonItem `json:"items"`
+	EquipmentHeavy bool       `json:"equipment_heavy"`
+	Client         string     `json:"client,omitempty"`
+	Contact        string     `json:"contact,omitempty"`
+	Phone          string     `json:"phone,omitempty"`
+	EndTime        string     `json:"end_time,omitempty"`
+	ServiceType    string     `json:"service_type,omitempty"`
+	Adjustments    []string   `json:"adjustments,omitempty"`
+	// Distance before the daily cap, when the order was capped
+	UncappedDistance float64 `json:"uncapped_distance,omitempty"`
+}
+
+type jsonItem struct {
+	Quantity  float64  `json:"quantity"`
+	Name      string   `json:"name"`
+	Notes     []string `json:"notes,omitempty"`
+	Equipment bool     `json:"equipment"`
+}
+
+type jsonRoute struct {
+	Date            string   `json:"date"`
+	Origin          string   `json:"origin"`
+	OrderIDs        []string `json:"order_ids"`
+	NaiveDistance   float64  `json:"naive_distance"`
+	ChainedDistance float64  `json:"chained_distance"`
+}
+
+type jsonPaid struct {
+	Employee  string  `json:"employee"`
+	OrderID   string  `json:"order_id"`
+	File      string  `json:"file"`
+	Distance  float64 `json:"distance"`
+	Date      string  `json:"date"`
+	PaidRunID string  `json:"paid_run_id"`
+	PaidBy    string  `json:"paid_by"`
+	PaidFile  string  `json:"paid_file"`
+	Included  bool    `json:"included"`
+}
+
+type jsonDuplicate struct {
+	Reason string              `json:"reason"`
+	Orders []jsonDuplicateCopy `json:"orders"`
+}
+
+type jsonDuplicateCopy struct {
+	Employee string  `json:"employee"`
+	File     string  `json:"file"`
+	OrderID  string  `json:"order_id"`
+	Date     string  `json:"date"`
+	Distance float64 `json:"distance"`
+	Excluded bool    `json:"excluded"`
+}
+
+type jsonError struct {
+	Employee    string `json:"employee"`
+	File        string `json:"file"`
+	Reason      string `json:"reason"`
+	Message     string `json:"message"`
+	OrderID     string `json:"order_id,omitempty"`
+	Destination string `json:"destination,omitempty"`
+}
+
+// CreateJSONFile writes the full report, errors included, for dashboards.
+// Employee totals use the chained figures in chain mode, as the workbook does.
+func CreateJSONFile(report Report, outputPath string) error {
+	output := jsonReport{
+		Title:          "Order Mileage",
+		RunID:          report.RunID,
+		Unit:           travel.OutputUnit().Name,
+		Employees:      []jsonEmployee{},
+		PreviouslyPaid: []jsonPaid{},
+		Duplicates:     []jsonDuplicate{},
+		Errors:         []jsonError{},
+	}
+	if report.Period != nil {
+		output.Title = fmt.Sprintf("Order Mileage %s", report.Period)
+		output.Period = &jsonPeriod{
+			Start: report.Period.Start.Format("2006-01-02"),
+			End:   report.Period.End.Format("2006-01-02"),
+		}
+	}
+
+	employees := make([]string, 0, len(report.Orders))
+	for employee := range report.Orders {
+		employees = append(employees, employee)
+	}
+
+	for _, name := range sortEmployees(report.Config, employees) {
+		employee := jsonEmployee{Name: name, Orders: []jsonOrder{}}
+
+		for _, order := range report.Orders[name] {
+			entry := jsonOrder{
+				OrderID:          order.OrderID,
+				Distance:         order.Mileage,
+				TripModel:        string(order.TripModel),
+				Date:             order.Date,
+				EventTime:        order.EventTime.Format(time.RFC3339),
+				Origin:           order.Origin,
+				OriginAddress:    order.OriginAddress,
+				Destination:      order.Destination,
+				File:             order.Filename,
+				Items:            []jsonItem{},
+				EquipmentHeavy:   order.EquipmentHeavy,
+				Client:           order.Client,
+				Contact:          order.Contact,
+				Phone:            order.Phone,
+				EndTime:          order.EndTime,
+				ServiceType:      order.ServiceType,
+				Adjustments:      order.Adjustments,
+				UncappedDistance: order.UncappedMileage,
+			}
+			for _, item := range order.Items {
+				entry.Items = append(entry.Items, jsonItem{
+					Quantity:  item.Quantity,
+					Name:      item.Name,
+					Notes:     item.Notes,
+					Equipment: item.IsEquipment(),
+				})
+			}
+			if perMile, ok := rateFor(report.Config, order.EventTime); ok {
+				amount := reimbursement(order.Mileage, perMile)
+				entry.Rate = &perMile
+				entry.Reimbursement = &amount
+				employee.Reimbursement += amount
+			}
+
+			employee.Distance += order.Mileage
+			employee.Orders = append(employee.Orders, entry)
+		}
+
+		for _, route := range report.Routes[name] {
+			employee.Routes = append(employee.Routes, jsonRoute{
+				Date:            route.Date,
+				Origin:          route.Origin,
+				OrderIDs:        route.OrderIDs,
+				NaiveDistance:   route.NaiveMileage,
+				ChainedDistance: route.ChainedMileage,
+			})
+
+			saved := route.NaiveMileage - route.ChainedMileage
+			employee.Distance -= saved
+			if date, err := time.Parse("2006-01-02", route.Date); err == nil {
+				if perMile, ok := rateFor(report.Config, date); ok {
+					employee.Reimbursement -= reimbursement(saved, perMile)
+				}
+			}
+		}
+
+		employee.Distance = travel.Round(employee.Distance)
+		employee.Reimbursement = math.Round(employee.Reimbursement*100) / 100
+		output.Employees = append(output.Employees, employee)
+	}
+
+	for _, paid := range report.Paid {
+		output.PreviouslyPaid = append(output.PreviouslyPaid, jsonPaid{
+			Employee:  paid.Employee,
+			OrderID:   paid.Order.OrderID,
+			File:      paid.Order.Filename,
+			Distance:  paid.Order.Mileage,
+			Date:      paid.Order.Date,
+			PaidRunID: paid.Entry.RunID,
+			PaidBy:    paid.Entry.Employee,
+			PaidFile:  paid.Entry.Filename,
+			Included:  paid.Included,
+		})
+	}
+
+	for _, duplicate := range report.Duplicates {
+		entry := jsonDuplicate{Reason: duplicate.Reason}
+		for _, order := range duplicate.Orders {
+			entry.Orders = append(entry.Orders, jsonDuplicateCopy{
+				Employee: order.Employee,
+				File:     order.Order.Filename,
+				OrderID:  order.Order.OrderID,
+				Date:     order.Order.Date,
+				Distance: order.Order.Mileage,
+				Excluded: order.Excluded,
+			})
+		}
+		output.Duplicates = append(output.Duplicates, entry)
+	}
+
+	for _, failure := range sortErrors(report.Config, report.Errors) {
+		reason := string(failure.Kind)
+		if reason == "" {
+			reason = "Unknown"
+		}
+		output.Errors = append(output.Errors, jsonError{
+			Employee:    failure.Employee,
+			File:        failure.Filename,
+			Reason:      reason,
+			Message:     failure.Message,
+			OrderID:     failure.Header.OrderID,
+			Destination: failure.Header.Destination,
+		})
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// Per-mile rate for an event on the date, if the config has one
+func rateFor(cfg *config.Config, date time.Time) (float64, bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	return cfg.RateFor(date)
+}
+
+// Reimbursement for a distance in the output unit, rounded to cents the same
+// way the workbook formulas do
+func reimbursement(distance, perMile float64) float64 {
+	miles := travel.Miles.FromMeters(travel.OutputUnit().ToMeters(distance))
+	return math.Round(miles*perMile*100) / 100
+}
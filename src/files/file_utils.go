@@ -41,7 +41,7 @@ func getCutsheets(files []os.DirEntry) ([]os.DirEntry, error) {
 	return cutsheets, nil
 }
 
-func CreateExcelFile(employeeOrders map[string][]orderInfo, errors []errorInfo) error {
+func CreateExcelFile(report Report, outputPath string) error {
 	f := excelize.NewFile()
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -49,12 +49,33 @@ func CreateExcelFile(employeeOrders map[string][]orderInfo, errors []errorInfo)
 		}
 	}()
 
-	// Rainbow colors
-	colors := []string{"#FF0000", "#FF7F00", "#FFFF00", "#00FF00", "#0000FF", "#8B00FF"}
-	colorIndex := 0
+	title := "Order Mileage"
+	if report.Period != nil {
+		title = fmt.Sprintf("Order Mileage %s", report.Period)
+	}
+	f.SetDocProps(&excelize.DocProperties{Title: title, Identifier: report.RunID})
+
+	// Roll everyone up on a first sheet when there's more than one employee
+	summaries := make(map[string]*employeeSummary)
+	employees := make([]string, 0, len(report.Orders))
+	for employee, orders := range report.Orders {
+		summaries[employee] = &employeeSummary{employee: employee, orders: len(orders)}
+		employees = append(employees, employee)
+	}
+	for _, failure := range report.Errors {
+		if summaries[failure.Employee] == nil {
+			summaries[failure.Employee] = &employeeSummary{employee: failure.Employee}
+		}
+		summaries[failure.Employee].errors++
+	}
 
 	var firstSheet string
-	for employee, orders := range employeeOrders {
+	if len(summaries) > 1 {
+		firstSheet = summarySheetName
+		f.SetSheetName(f.GetSheetName(0), summarySheetName)
+	}
+
+	for _, employee := range sortEmployees(report.Config, employees) {
 		// Use employee name as sheet name, replacing any invalid characters
 		sheetName := sanitizeSheetName(employee)
 		index, err := f.NewSheet(sheetName)
@@ -67,56 +88,14 @@ func CreateExcelFile(employeeOrders map[string][]orderInfo, errors []errorInfo)
 			firstSheet = sheetName
 		}
 
-		// Set employee name and color
-		f.SetCellValue(sheetName, "A1", employee)
-		bgColor := colors[colorIndex]
-		textColor := getContrastColor(bgColor)
-		style, _ := f.NewStyle(&excelize.Style{
-			Fill: excelize.Fill{Type: "pattern", Color: []string{bgColor}, Pattern: 1},
-			Font: &excelize.Font{Bold: true, Color: textColor},
-		})
-		f.SetCellStyle(sheetName, "A1", "A1", style)
-
-		// Set headers
-		headers := []string{"Order ID", "Mileage", "Date", "Origin", "Destination"}
-		for col, header := range headers {
-			cell := string(rune('A'+col)) + "2"
-			f.SetCellValue(sheetName, cell, header)
-		}
-
-		// Set header style
-		headerStyle, _ := f.NewStyle(&excelize.Style{
-			Font: &excelize.Font{Bold: true},
-			Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
-		})
-		f.SetCellStyle(sheetName, "A2", "E2", headerStyle)
-
-		// Fill in order data
-		row := 3
-		totalMileage := 0.0
-		for _, order := range orders {
-			f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), order.OrderID)
-			f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), order.Mileage)
-			f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), order.Date)
-			f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), order.Origin)
-			f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), order.Destination)
-			totalMileage += order.Mileage
-			row++
+		summary := summaries[employee]
+		summary.sheetName = sheetName
+		if err := writeEmployeeSheet(f, report, employee, summary, index); err != nil {
+			return err
 		}
 
-		// Set total mileage with the same color scheme as the employee header
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row+1), "Total Mileage:")
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row+1), totalMileage)
-		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row+1), fmt.Sprintf("B%d", row+1), style)
-
-		// Set column widths
-		f.SetColWidth(sheetName, "A", "E", 15)
-
 		// Set active sheet
 		f.SetActiveSheet(index)
-
-		// Move to next color
-		colorIndex = (colorIndex + 1) % len(colors)
 	}
 
 	defaultSheet := f.GetSheetName(0)
@@ -124,15 +103,28 @@ func CreateExcelFile(employeeOrders map[string][]orderInfo, errors []errorInfo)
 		f.DeleteSheet(defaultSheet)
 	}
 
+	if firstSheet == summarySheetName {
+		writeSummary(f, report.Config, summaries)
+	}
+
 	// Set the first created sheet as active
 	if firstSheet != "" {
 		firstSheetIndex, _ := f.GetSheetIndex(firstSheet)
 		f.SetActiveSheet(firstSheetIndex)
 	}
 
+	if len(report.Duplicates) > 0 {
+		writeDuplicatesSheet(f, report.Duplicates)
+	}
+
+	if len(report.Paid) > 0 {
+		writePaidSheet(f, report)
+	}
+
 	// Add error information (same as before)
+	errors := sortErrors(report.Config, report.Errors)
 	if len(errors) == 0 {
-		return f.SaveAs("orders_report.xlsx")
+		return f.SaveAs(outputPath)
 	}
 
 	errorSheetName := "Errors"
@@ -144,11 +136,18 @@ func CreateExcelFile(employeeOrders map[string][]orderInfo, errors []errorInfo)
 	})
 	f.SetCellStyle(errorSheetName, "A1", "A1", errorHeaderStyle)
 
-	row := 2
+	setRow(f, errorSheetName, 2, []string{"File", "Reason", "Order ID", "Destination", "Message"})
+	errorColumnStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	f.SetCellStyle(errorSheetName, "A2", "E2", errorColumnStyle)
+
+	row := 3
 	currentEmployee := ""
 	for _, err := range errors {
 		if err.Employee != currentEmployee {
-			if row > 2 {
+			if row > 3 {
 				row++ // Add a blank row between employees
 			}
 			f.SetCellValue(errorSheetName, fmt.Sprintf("A%d", row), err.Employee)
@@ -159,14 +158,42 @@ func CreateExcelFile(employeeOrders map[string][]orderInfo, errors []errorInfo)
 			row++
 			currentEmployee = err.Employee
 		}
-		f.SetCellValue(errorSheetName, fmt.Sprintf("A%d", row), err.Filename)
+		reason := string(err.Kind)
+		if reason == "" {
+			reason = "Unknown"
+		}
+		setRow(f, errorSheetName, row, []string{
+			err.Filename,
+			reason,
+			err.Header.OrderID,
+			err.Header.Destination,
+			err.Message,
+		})
 		row++
 	}
 
 	f.SetColWidth(errorSheetName, "A", "A", 30)
+	f.SetColWidth(errorSheetName, "B", "C", 15)
+	f.SetColWidth(errorSheetName, "D", "E", 40)
 
 	// Save the Excel file
-	return f.SaveAs("orders_report.xlsx")
+	return f.SaveAs(outputPath)
+}
+
+// Writes values into consecutive cells of a row, starting at column A
+func setRow[T any](f *excelize.File, sheetName string, row int, values []T) {
+	for col, value := range values {
+		cell, _ := excelize.CoordinatesToCellName(col+1, row)
+		f.SetCellValue(sheetName, cell, value)
+	}
+}
+
+// Number format showing distances with a fixed number of decimals
+func distanceNumberFormat(precision int) string {
+	if precision <= 0 {
+		return "#,##0"
+	}
+	return "#,##0." + strings.Repeat("0", precision)
 }
 
 func sanitizeSheetName(name string) string {
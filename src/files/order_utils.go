@@ -1,34 +1,116 @@
 package fileutils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/jlsnow301/cutsheet-traveller/config"
 	"github.com/jlsnow301/cutsheet-traveller/header"
+	"github.com/jlsnow301/cutsheet-traveller/items"
+	"github.com/jlsnow301/cutsheet-traveller/ledger"
 	timeutils "github.com/jlsnow301/cutsheet-traveller/time"
 	"github.com/jlsnow301/cutsheet-traveller/travel"
 	"github.com/jlsnow301/cutsheet-traveller/utils"
 )
 
+var envKeyRe = regexp.MustCompile(`[^A-Z0-9]+`)
+
 type errorInfo struct {
 	Employee string
 	Filename string
+	Kind     ErrorKind
+	Message  string
+	// Whatever could be parsed before the failure
+	Header header.HeaderInfo
 }
 
 type orderInfo struct {
-	OrderID     string
-	Mileage     float64
-	Date        string
-	Origin      string
-	Destination string
+	OrderID       string
+	Mileage       float64
+	TripModel     travel.TripModel
+	Date          string
+	EventTime     time.Time
+	Origin        string
+	OriginAddress string
+	Destination   string
+	Filename      string
+	// SHA-256 of the cut sheet, to recognize it under another name
+	FileHash string
+	Items    []items.Item
+	// Enough equipment to justify going back for it
+	EquipmentHeavy bool
+	Client         string
+	Contact        string
+	Phone          string
+	EndTime        string
+	ServiceType    string
+	// Employee settings that changed how the order was reimbursed
+	Adjustments []string
+	// Distance before the employee's daily cap, when it was capped
+	UncappedMileage float64
+}
+
+// Report is everything collected from the employee folders.
+type Report struct {
+	Orders map[string][]orderInfo
+	Errors []errorInfo
+	// Chained daily routes per employee, only filled in chain mode
+	Routes map[string][]routeInfo
+	// Period the report covers, or nil for everything
+	Period *timeutils.Period
+	// Config the report was collected with, for reimbursement rates
+	Config *config.Config
+	// RunID identifies this run in the ledger
+	RunID string
+	// Orders the ledger says were already reimbursed
+	Paid []paidInfo
+	// Cut sheets for the same order, within or across employees
+	Duplicates []duplicateInfo
+	// Optional columns added to each employee's orders
+	Columns []Column
+}
+
+// CollectOptions changes how cut sheets are collected.
+type CollectOptions struct {
+	// Chain combines an employee's round trips on the same day into one route
+	Chain bool
+	// NonInteractive records missing event times as errors instead of prompting
+	NonInteractive bool
+	// Period skips cut sheets for events outside it, when set
+	Period *timeutils.Period
+	// Config holds the known origins
+	Config *config.Config
+	// Workers is how many cut sheets are processed at once
+	Workers int
+	// Ledger of orders already reimbursed, which are left out of the report
+	Ledger *ledger.Ledger
+	// IncludePaid keeps orders found in the ledger in the report
+	IncludePaid bool
+	// RunID identifies this run in the ledger
+	RunID string
+	// ExcludeDuplicates counts only the first copy of a duplicated order
+	ExcludeDuplicates bool
 }
 
+// Returned for cut sheets outside the report period; they are skipped, not errors
+var errOutsidePeriod = errors.New("event is outside the report period")
+
+// Returned for cut sheets the ledger says were already reimbursed, before
+// their distance is looked up
+var errAlreadyPaid = errors.New("order was already reimbursed")
+
 // Collect all orders and errors
-func CollectOrdersAndErrors(foldersToSearch []string, employeesDir string) (map[string][]orderInfo, []errorInfo) {
+func CollectOrdersAndErrors(foldersToSearch []string, employeesDir string, options CollectOptions) Report {
 	employeeOrders := make(map[string][]orderInfo)
-	var errors []errorInfo
+	var orderErrors []errorInfo
+	var paid []paidInfo
 
 	for _, searchFolder := range foldersToSearch {
 		folderPath := filepath.Join(employeesDir, searchFolder)
@@ -43,71 +125,250 @@ func CollectOrdersAndErrors(foldersToSearch []string, employeesDir string) (map[
 			continue
 		}
 
-		for _, cutsheet := range cutsheets {
-			pdfPath := filepath.Join(folderPath, cutsheet.Name())
+		// Process the cut sheets in parallel, keeping the results in folder order
+		type result struct {
+			order orderInfo
+			err   error
+		}
+		results := make([]result, len(cutsheets))
+		progress := newProgress(searchFolder, len(cutsheets))
+
+		runPool(len(cutsheets), options.Workers, func(i int) {
+			pdfPath := filepath.Join(folderPath, cutsheets[i].Name())
+			order, err := getOrderInfo(pdfPath, searchFolder, options)
+			results[i] = result{order: order, err: err}
+			progress.increment()
+		})
+		progress.finish()
 
-			orderInfo, err := getOrderInfo(pdfPath)
+		for i, cutsheet := range cutsheets {
+			orderInfo, err := results[i].order, results[i].err
+			if errors.Is(err, errOutsidePeriod) {
+				continue
+			}
+			if errors.Is(err, errAlreadyPaid) {
+				entry, _ := options.Ledger.Paid(orderInfo.OrderID, orderInfo.FileHash)
+				paid = append(paid, paidInfo{Employee: searchFolder, Order: orderInfo, Entry: entry})
+				utils.PrintYellow(fmt.Sprintf("%s: already paid in run %s, skipping", cutsheet.Name(), entry.RunID))
+				continue
+			}
 			if err != nil {
-				errors = append(errors, errorInfo{
+				failure := errorInfo{
 					Employee: searchFolder,
 					Filename: cutsheet.Name(),
-				})
+					Message:  err.Error(),
+				}
+
+				var orderErr *orderError
+				if errors.As(err, &orderErr) {
+					failure.Kind = orderErr.Kind
+					failure.Message = orderErr.Message
+					failure.Header = orderErr.Header
+				}
+
+				utils.PrintRed(fmt.Sprintf("%s: %s", cutsheet.Name(), failure.Message))
+				orderErrors = append(orderErrors, failure)
 				continue
 			}
 
+			// Paid orders only get this far when they're kept in the report
+			if options.Ledger != nil {
+				if entry, ok := options.Ledger.Paid(orderInfo.OrderID, orderInfo.FileHash); ok {
+					paid = append(paid, paidInfo{Employee: searchFolder, Order: orderInfo, Entry: entry, Included: true})
+				}
+			}
+
 			employeeOrders[searchFolder] = append(employeeOrders[searchFolder], orderInfo)
 		}
 	}
 
-	return employeeOrders, errors
+	for _, orders := range employeeOrders {
+		sortOrders(orders)
+	}
+
+	duplicates := findDuplicates(options.Config, employeeOrders)
+	if options.ExcludeDuplicates {
+		excludeDuplicates(employeeOrders, duplicates)
+	}
+
+	report := Report{
+		Orders:     employeeOrders,
+		Errors:     orderErrors,
+		Period:     options.Period,
+		Config:     options.Config,
+		RunID:      options.RunID,
+		Paid:       paid,
+		Duplicates: duplicates,
+	}
+	if options.Chain {
+		report.Routes = chainRoutes(employeeOrders)
+	}
+	applyDailyCaps(options.Config, employeeOrders, report.Routes)
+	updateDuplicates(employeeOrders, report.Duplicates)
+
+	return report
 }
 
 // Get the order info from a PDF file
-func getOrderInfo(pdfPath string) (orderInfo, error) {
+func getOrderInfo(pdfPath, employee string, options CollectOptions) (orderInfo, error) {
 	pdfText, err := utils.ExtractTextFromPDF(pdfPath)
 	if err != nil {
-		utils.PrintRed(fmt.Sprintf("Error extracting text from PDF: %v", err))
-		return orderInfo{}, err
+		return orderInfo{}, newOrderError(PDFUnreadable, header.HeaderInfo{}, "Error extracting text from PDF: %v", err)
+	}
+
+	fileHash, err := hashFile(pdfPath)
+	if err != nil {
+		return orderInfo{}, newOrderError(PDFUnreadable, header.HeaderInfo{}, "Error reading PDF: %v", err)
 	}
 
-	headerText, _ := utils.SplitTexts(pdfText)
-	headerInfo := header.ParseHeaderInfo(headerText)
+	headerText, itemText := utils.SplitTexts(pdfText)
+	headerInfo := header.ParseHeaderInfo(headerText, options.Config)
 	if headerInfo.Destination == "" {
-		utils.PrintRed("Unable to determine destination address.")
-		return orderInfo{}, err
+		return orderInfo{}, newOrderError(NoDestination, headerInfo, "Unable to determine destination address.")
 	}
 
-	origin := headerInfo.Origin
-	if origin == "" {
-		utils.PrintRed("No origin specified.")
-		return orderInfo{}, err
+	if options.Period != nil {
+		if headerInfo.EventDate.IsZero() {
+			return orderInfo{}, newOrderError(BadTime, headerInfo, "Unable to determine event date.")
+		}
+		if !options.Period.Contains(headerInfo.EventDate) {
+			return orderInfo{}, errOutsidePeriod
+		}
 	}
 
-	originAddress := os.Getenv(strings.ToUpper(origin) + "_ADDRESS")
-	if originAddress == "" {
-		utils.PrintRed(fmt.Sprintf("Unknown origin: %s", headerInfo.Origin))
-		return orderInfo{}, err
+	// Orders left out as paid don't need a distance looked up
+	if options.Ledger != nil && !options.IncludePaid {
+		if entry, ok := options.Ledger.Paid(headerInfo.OrderID, fileHash); ok {
+			return paidOrder(pdfPath, fileHash, headerInfo, entry), errAlreadyPaid
+		}
 	}
 
-	eventTime, err := timeutils.GetEventTime(headerInfo.EventDate, headerInfo.EventTime)
+	settings := options.Config.FindEmployee(employee)
+	var adjustments []string
+
+	// Drivers who start somewhere else don't need the cut sheet's origin
+	origin := options.Config.OriginOverride(settings)
+	if origin != nil {
+		cutSheetOrigin := headerInfo.Origin
+		if cutSheetOrigin == "" {
+			cutSheetOrigin = "none"
+		}
+		adjustments = append(adjustments, fmt.Sprintf("Origin %s instead of %s", origin.Name, cutSheetOrigin))
+	} else {
+		if headerInfo.Origin == "" {
+			return orderInfo{}, newOrderError(UnknownOrigin, headerInfo, "No origin specified.")
+		}
+
+		origin = options.Config.FindOrigin(headerInfo.Origin)
+		if origin == nil {
+			return orderInfo{}, newOrderError(UnknownOrigin, headerInfo, "Unknown origin: %s", headerInfo.Origin)
+		}
+	}
+	originAddress := origin.RoutingAddress()
+
+	eventTime, err := timeutils.GetEventTime(headerInfo.EventDate, headerInfo.EventTime, filepath.Base(pdfPath), !options.NonInteractive)
+	if err != nil {
+		return orderInfo{}, newOrderError(BadTime, headerInfo, "Invalid event time (%v). Please use HH:MM AM/PM.", err)
+	}
+
+	tripModel, err := getTripModel(headerInfo, employee, settings, origin)
 	if err != nil {
-		utils.PrintRed("Invalid event time. Please use HH:MM AM/PM.")
-		return orderInfo{}, err
+		return orderInfo{}, newOrderError(BadTripModel, headerInfo, "%v", err)
 	}
 
-	distance, err := travel.GetBaseTravelDistance(originAddress, headerInfo.Destination, eventTime)
+	distance, err := travel.GetBaseTravelDistance(originAddress, headerInfo.Destination, eventTime, tripModel)
 	if err != nil {
-		utils.PrintRed(fmt.Sprintf("Unable to calculate travel time: %v", err))
-		return orderInfo{}, err
+		return orderInfo{}, newOrderError(RoutingFailed, headerInfo, "Unable to calculate travel time: %v", err)
 	}
 
 	orderInfo := orderInfo{
+		OrderID:       headerInfo.OrderID,
+		Mileage:       distance,
+		TripModel:     tripModel,
+		Date:          headerInfo.EventDate.Format("2006-01-02"),
+		EventTime:     *eventTime,
+		Origin:        origin.Name,
+		OriginAddress: originAddress,
+		Adjustments:   adjustments,
+		Destination:   headerInfo.Destination,
+		Filename:      filepath.Base(pdfPath),
+		FileHash:      fileHash,
+		Client:        headerInfo.Client,
+		Contact:       headerInfo.Contact,
+		Phone:         headerInfo.Phone,
+		EndTime:       headerInfo.EndTime,
+		ServiceType:   headerInfo.ServiceType,
+	}
+	orderInfo.Items = items.Parse(itemText)
+	orderInfo.EquipmentHeavy = items.EquipmentHeavy(orderInfo.Items)
+
+	return orderInfo, nil
+}
+
+// What's known of a paid order without routing it, with the distance it was
+// paid for
+func paidOrder(pdfPath, fileHash string, headerInfo header.HeaderInfo, entry ledger.Entry) orderInfo {
+	order := orderInfo{
 		OrderID:     headerInfo.OrderID,
-		Mileage:     distance,
-		Date:        headerInfo.EventDate.Format("2006-01-02"),
-		Origin:      headerInfo.Origin,
 		Destination: headerInfo.Destination,
+		Filename:    filepath.Base(pdfPath),
+		FileHash:    fileHash,
+	}
+	if !headerInfo.EventDate.IsZero() {
+		order.Date = headerInfo.EventDate.Format("2006-01-02")
+	}
+	if unit, err := travel.ParseUnit(entry.Unit); err == nil {
+		order.Mileage = travel.Round(travel.OutputUnit().FromMeters(unit.ToMeters(entry.Mileage)))
+	}
+	return order
+}
+
+// Picks the trip model from the cut sheet, or its service type, falling back
+// to the employee's, then the origin's, then the global default from the
+// environment.
+func getTripModel(headerInfo header.HeaderInfo, employee string, settings *config.Employee, origin *config.Origin) (travel.TripModel, error) {
+	if headerInfo.TripModel != "" {
+		return travel.ParseTripModel(headerInfo.TripModel)
 	}
 
-	return orderInfo, nil
+	if headerInfo.HasPickup {
+		return travel.DropoffPickup, nil
+	}
+
+	if tripModel, ok := travel.ServiceTripModel(headerInfo.ServiceType); ok {
+		return tripModel, nil
+	}
+
+	if settings != nil && settings.TripModel != "" {
+		return travel.ParseTripModel(settings.TripModel)
+	}
+
+	if value := os.Getenv(envKey(employee) + "_TRIP_MODEL"); value != "" {
+		return travel.ParseTripModel(value)
+	}
+
+	if origin.TripModel != "" {
+		return travel.ParseTripModel(origin.TripModel)
+	}
+
+	if value := os.Getenv("TRIP_MODEL"); value != "" {
+		return travel.ParseTripModel(value)
+	}
+
+	return travel.RoundTrip, nil
+}
+
+// Turns a name into the prefix used for its environment variables
+func envKey(name string) string {
+	return strings.Trim(envKeyRe.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
+
+// SHA-256 of a file's contents, hex encoded
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
 }
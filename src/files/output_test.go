@@ -0,0 +1,28 @@
+package fileutils
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestIsSharingViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"sharing violation", &fs.PathError{Op: "open", Path: "report.xlsx", Err: syscall.Errno(32)}, true},
+		{"lock violation", &fs.PathError{Op: "open", Path: "report.xlsx", Err: syscall.Errno(33)}, true},
+		{"message only", errors.New("The process cannot access the file because it is being used by another process."), true},
+		{"permission denied", &fs.PathError{Op: "open", Path: "report.xlsx", Err: fs.ErrPermission}, false},
+		{"other error", &fs.PathError{Op: "open", Path: "report.xlsx", Err: syscall.Errno(2)}, false},
+	}
+
+	for _, test := range tests {
+		if got := isSharingViolation(test.err); got != test.want {
+			t.Errorf("isSharingViolation(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
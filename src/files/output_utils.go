@@ -0,0 +1,71 @@
+package fileutils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	timeutils "github.com/jlsnow301/cutsheet-traveller/time"
+)
+
+// DefaultOutputTemplate keeps every run's report instead of overwriting the last one.
+const DefaultOutputTemplate = "reports/{period}_{employee}_{timestamp}.xlsx"
+
+// OutputPath fills in an output path template. {period} is the report period,
+// {employee} the employee when there's only one and "all" otherwise, and
+// {timestamp} the time of the run.
+func OutputPath(template string, period *timeutils.Period, employees []string, now time.Time) string {
+	periodText := "all-dates"
+	if period != nil {
+		periodText = period.Slug()
+	}
+
+	employee := "all"
+	if len(employees) == 1 {
+		employee = strings.ReplaceAll(sanitizeSheetName(employees[0]), " ", "_")
+	}
+
+	return strings.NewReplacer(
+		"{period}", periodText,
+		"{employee|all}", employee,
+		"{employee}", employee,
+		"{timestamp}", now.Format("20060102-150405"),
+	).Replace(template)
+}
+
+// PrepareOutput makes sure a report can be written to the path: its folder
+// exists and, unless force is set, there isn't already a report there.
+func PrepareOutput(path string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists, pass --force to overwrite it", path)
+	}
+
+	return os.MkdirAll(filepath.Dir(path), 0755)
+}
+
+// Windows errors for a file another program has open
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// IsLocked reports whether writing failed because another program, usually
+// Excel, has the file open. Other errors, like a read-only folder, aren't
+// going to go away by trying again.
+func IsLocked(err error) bool {
+	return runtime.GOOS == "windows" && isSharingViolation(err)
+}
+
+// Whether err is Windows refusing access to a file another process has open
+func isSharingViolation(err error) bool {
+	var errno syscall.Errno
+	if errors.As(err, &errno) && (errno == errorSharingViolation || errno == errorLockViolation) {
+		return true
+	}
+	return strings.Contains(err.Error(), "being used by another process")
+}
@@ -0,0 +1,113 @@
+package fileutils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/jlsnow301/cutsheet-traveller/ledger"
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+const paidSheetName = "Previously paid"
+
+// paidInfo is an order the ledger says was reimbursed in an earlier run.
+type paidInfo struct {
+	Employee string
+	Order    orderInfo
+	// The earliest ledger entry for the order or its cut sheet
+	Entry ledger.Entry
+	// Whether the order was kept in the report anyway (--include-paid)
+	Included bool
+}
+
+// RecordPaid adds every order in the report to the ledger, except those it
+// already has and later copies of an order or cut sheet seen in this report.
+func RecordPaid(report Report, l *ledger.Ledger, now time.Time) error {
+	employees := make([]string, 0, len(report.Orders))
+	for employee := range report.Orders {
+		employees = append(employees, employee)
+	}
+
+	var entries []ledger.Entry
+	seenOrders := make(map[string]bool)
+	seenHashes := make(map[string]bool)
+	for _, employee := range sortEmployees(report.Config, employees) {
+		for _, order := range report.Orders[employee] {
+			if _, ok := l.Paid(order.OrderID, order.FileHash); ok {
+				continue
+			}
+			if seenOrders[order.OrderID] || seenHashes[order.FileHash] {
+				continue
+			}
+			seenOrders[order.OrderID] = true
+			seenHashes[order.FileHash] = true
+
+			entries = append(entries, ledger.Entry{
+				RunID:      report.RunID,
+				RecordedAt: now,
+				Employee:   employee,
+				OrderID:    order.OrderID,
+				Filename:   order.Filename,
+				FileHash:   order.FileHash,
+				Date:       order.Date,
+				Mileage:    order.Mileage,
+				Unit:       travel.OutputUnit().Name,
+			})
+		}
+	}
+
+	return l.Record(entries)
+}
+
+// Lists the orders already reimbursed and which run paid them
+func writePaidSheet(f *excelize.File, report Report) {
+	f.NewSheet(paidSheetName)
+
+	f.SetCellValue(paidSheetName, "A1", "Previously paid")
+	titleStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 14},
+	})
+	f.SetCellStyle(paidSheetName, "A1", "A1", titleStyle)
+
+	distanceHeader := fmt.Sprintf("Distance (%s)", travel.OutputUnit().Label)
+	setRow(f, paidSheetName, 2, []string{"Employee", "Order ID", "File", distanceHeader, "Date", "Paid in run", "Paid by", "Paid file", "Status"})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	f.SetCellStyle(paidSheetName, "A2", "I2", headerStyle)
+
+	distanceFormat := distanceNumberFormat(travel.OutputPrecision())
+	distanceStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &distanceFormat})
+	dateStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+
+	row := 3
+	for _, paid := range report.Paid {
+		status := "Left out"
+		if paid.Included {
+			status = "Included"
+		}
+
+		setRow(f, paidSheetName, row, []any{
+			paid.Employee,
+			paid.Order.OrderID,
+			paid.Order.Filename,
+			paid.Order.Mileage,
+			excelDate(paid.Order.Date),
+			paid.Entry.RunID,
+			paid.Entry.Employee,
+			paid.Entry.Filename,
+			status,
+		})
+		row++
+	}
+
+	f.SetCellStyle(paidSheetName, "D3", fmt.Sprintf("D%d", row-1), distanceStyle)
+	f.SetCellStyle(paidSheetName, "E3", fmt.Sprintf("E%d", row-1), dateStyle)
+
+	f.SetColWidth(paidSheetName, "A", "I", 15)
+	f.SetColWidth(paidSheetName, "C", "C", 30)
+	f.SetColWidth(paidSheetName, "H", "H", 30)
+}
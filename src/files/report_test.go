@@ -0,0 +1,323 @@
+package fileutils
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
+	"github.com/jlsnow301/cutsheet-traveller/ledger"
+	"github.com/jlsnow301/cutsheet-traveller/travel/faketravel"
+	"github.com/jlsnow301/cutsheet-traveller/utils/fakepdf"
+)
+
+const (
+	fremontAddress = "3500 Stone Way N, Seattle WA"
+	thirdAve       = "1201 3rd Ave, Seattle WA"
+	broadSt        = "400 Broad St, Seattle WA"
+	nowhereRd      = "1 Nowhere Rd, Seattle WA"
+)
+
+// Writes Jane Doe's cut sheets: two deliveries from Fremont on the same day,
+// one to an address with no route and one from an unknown kitchen
+func writeEmployees(t *testing.T) string {
+	t.Helper()
+
+	sheets := map[string][]string{
+		"S41001.pdf": {"S41001", "Monday, 3/2/2026", "Fremont", "Site Address: 1201 3rd Ave", "Seattle WA", "Headcount: 40", "Start Time: 11:30 AM", "Food/Service Item", "40 Box Lunch"},
+		"S41002.pdf": {"S41002", "Monday, 3/2/2026", "Fremont", "Site Address: 400 Broad St", "Seattle WA", "Headcount: 12", "Start Time: 2:00 PM", "Food/Service Item", "12 Box Lunch"},
+		"S41003.pdf": {"S41003", "Tuesday, 3/3/2026", "Fremont", "Site Address: 1 Nowhere Rd", "Seattle WA", "Headcount: 8", "Start Time: 9:00 AM", "Food/Service Item"},
+		"S41004.pdf": {"S41004", "Tuesday, 3/3/2026", "Ballard", "Site Address: 400 Broad St", "Seattle WA", "Headcount: 8", "Start Time: 9:00 AM", "Food/Service Item"},
+	}
+
+	dir := t.TempDir()
+	folder := filepath.Join(dir, "Jane Doe")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, lines := range sheets {
+		if err := os.WriteFile(filepath.Join(folder, name), fakepdf.Lines(lines...), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func newFakeDirections(t *testing.T) *faketravel.Server {
+	t.Helper()
+	t.Setenv("TRIP_MODEL", "")
+
+	server := faketravel.NewServer(t)
+	server.SetDistance(fremontAddress, thirdAve, 8047)
+	server.SetDistance(fremontAddress, broadSt, 4828)
+	server.SetDistance(thirdAve, broadSt, 2414)
+	server.SetDistance(broadSt, fremontAddress, 4828)
+	server.SetStatus(fremontAddress, nowhereRd, "ZERO_RESULTS")
+	server.Install(t)
+
+	return server
+}
+
+func testReportConfig() *config.Config {
+	return &config.Config{
+		Origins: []config.Origin{{Name: "Fremont", Address: fremontAddress}},
+		Rates:   []config.Rate{{PerMile: 0.5}},
+	}
+}
+
+// Collects and writes a report, returning the opened workbook
+func writeReport(t *testing.T, options CollectOptions) *excelize.File {
+	t.Helper()
+
+	report := CollectOrdersAndErrors([]string{"Jane Doe"}, writeEmployees(t), options)
+
+	outputPath := filepath.Join(t.TempDir(), "report.xlsx")
+	if err := CreateExcelFile(report, outputPath); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
+
+func checkCells(t *testing.T, f *excelize.File, sheet string, want map[string]string) {
+	t.Helper()
+
+	for cell, value := range want {
+		got, err := f.CalcCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+		if err != nil {
+			t.Errorf("%s!%s: %v", sheet, cell, err)
+			continue
+		}
+		if got != value {
+			t.Errorf("%s!%s = %q, want %q", sheet, cell, got, value)
+		}
+	}
+}
+
+func TestReportEndToEnd(t *testing.T) {
+	server := newFakeDirections(t)
+
+	f := writeReport(t, CollectOptions{Config: testReportConfig(), NonInteractive: true, Workers: 2})
+
+	if got, want := f.GetSheetList(), []string{"Jane Doe", "Errors"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("sheets = %v, want %v", got, want)
+	}
+
+	// Round trips at $0.50 a mile, totalled two rows below the orders
+	checkCells(t, f, "Jane Doe", map[string]string{
+		"A3": "S41001", "B3": "10", "D3": "0.5", "E3": "5", "G3": "Fremont", "H3": thirdAve,
+		"A4": "S41002", "B4": "6", "D4": "0.5", "E4": "3", "G4": "Fremont", "H4": broadSt,
+		"B6": "16", "E6": "8",
+	})
+
+	rows, err := f.GetRows("Errors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := make(map[string]string)
+	for _, row := range rows[2:] {
+		if len(row) > 1 {
+			reasons[row[0]] = row[1]
+		}
+	}
+	wantReasons := map[string]string{"S41003.pdf": string(RoutingFailed), "S41004.pdf": string(UnknownOrigin)}
+	for file, reason := range wantReasons {
+		if reasons[file] != reason {
+			t.Errorf("error for %s = %q, want %q", file, reasons[file], reason)
+		}
+	}
+
+	// The sheet without a known kitchen never gets as far as routing
+	if got := len(server.Requests()); got != 3 {
+		t.Errorf("got %d directions requests, want 3", got)
+	}
+}
+
+func TestReportEndToEndChained(t *testing.T) {
+	server := newFakeDirections(t)
+
+	f := writeReport(t, CollectOptions{Config: testReportConfig(), NonInteractive: true, Workers: 2, Chain: true})
+
+	// Fremont > 3rd Ave > Broad St > Fremont is 9.5 miles against 16 driven
+	// separately
+	checkCells(t, f, "Jane Doe", map[string]string{
+		"B6":  "16",
+		"B8":  "9.5",
+		"E8":  "4.75",
+		"C11": "S41001 > S41002",
+		"D11": "16",
+		"E11": "9.5",
+	})
+
+	var chained *faketravel.Request
+	for _, request := range server.Requests() {
+		if len(request.Waypoints) > 0 {
+			chained = &request
+		}
+	}
+	if chained == nil {
+		t.Fatal("no directions request with waypoints")
+	}
+	if chained.Origin != fremontAddress || chained.Destination != fremontAddress ||
+		strings.Join(chained.Waypoints, "|") != thirdAve+"|"+broadSt {
+		t.Errorf("chained request = %+v", *chained)
+	}
+}
+
+func TestReportSkipsPaidOrders(t *testing.T) {
+	server := newFakeDirections(t)
+
+	paidLedger, err := ledger.Open(filepath.Join(t.TempDir(), "ledger.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = paidLedger.Record([]ledger.Entry{{RunID: "earlier", Employee: "Jane Doe", OrderID: "S41001", Date: "2026-03-02", Mileage: 10, Unit: "miles"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := CollectOrdersAndErrors([]string{"Jane Doe"}, writeEmployees(t), CollectOptions{
+		Config:         testReportConfig(),
+		NonInteractive: true,
+		Ledger:         paidLedger,
+	})
+
+	if len(report.Paid) != 1 || report.Paid[0].Order.OrderID != "S41001" || report.Paid[0].Included {
+		t.Fatalf("paid = %+v, want S41001 left out", report.Paid)
+	}
+	if got := report.Paid[0].Order.Mileage; got != 10 {
+		t.Errorf("paid distance = %g, want 10", got)
+	}
+	if orders := report.Orders["Jane Doe"]; len(orders) != 1 || orders[0].OrderID != "S41002" {
+		t.Errorf("orders = %+v, want only S41002", orders)
+	}
+
+	// The paid order is never routed
+	for _, request := range server.Requests() {
+		if request.Destination == thirdAve {
+			t.Errorf("directions requested for a paid order: %+v", request)
+		}
+	}
+}
+
+func TestRecordPaidOncePerOrder(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.jsonl")
+	paidLedger, err := ledger.Open(ledgerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same order under two employees, and the same cut sheet renamed
+	report := Report{
+		RunID: "now",
+		Orders: map[string][]orderInfo{
+			"Jane Doe": {
+				{OrderID: "S41001", FileHash: "aaa", Date: "2026-03-02"},
+				{OrderID: "S41002", FileHash: "bbb", Date: "2026-03-02"},
+			},
+			"John Roe": {
+				{OrderID: "S41001", FileHash: "ccc", Date: "2026-03-02"},
+				{OrderID: "S41003", FileHash: "bbb", Date: "2026-03-02"},
+			},
+		},
+	}
+	if err := RecordPaid(report, paidLedger, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(ledgerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 2 {
+		t.Errorf("recorded %d entries, want 2:\n%s", got, data)
+	}
+}
+
+func TestCSVShowsDailyCap(t *testing.T) {
+	newFakeDirections(t)
+
+	cfg := testReportConfig()
+	cfg.Employees = []config.Employee{{Name: "Jane Doe", DailyMilesCap: 12}}
+	report := CollectOrdersAndErrors([]string{"Jane Doe"}, writeEmployees(t), CollectOptions{Config: cfg, NonInteractive: true})
+
+	outputPath := filepath.Join(t.TempDir(), "report.csv")
+	if err := CreateCSVFile(report, outputPath); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want a header and 2 orders", len(rows))
+	}
+	if got := strings.Join(rows[0][9:11], ","); got != "Adjustments,Uncapped Distance (mi)" {
+		t.Errorf("headers = %q", got)
+	}
+	// S41002's 6 miles are cut to what's left of the 12 after S41001's 10
+	want := [][]string{
+		{"S41001", "10.0", "", ""},
+		{"S41002", "2.0", "Capped at 12 mi/day", "6.0"},
+	}
+	for i, row := range rows[1:] {
+		got := []string{row[1], row[2], row[9], row[10]}
+		if strings.Join(got, "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i+1, got, want[i])
+		}
+	}
+}
+
+func TestDuplicatesShowCappedDistance(t *testing.T) {
+	newFakeDirections(t)
+
+	dir := writeEmployees(t)
+	sheet, err := os.ReadFile(filepath.Join(dir, "Jane Doe", "S41002.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Jane Doe", "S41002 copy.pdf"), sheet, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testReportConfig()
+	cfg.Employees = []config.Employee{{Name: "Jane Doe", DailyMilesCap: 12}}
+	report := CollectOrdersAndErrors([]string{"Jane Doe"}, dir, CollectOptions{Config: cfg, NonInteractive: true})
+
+	if len(report.Duplicates) != 1 {
+		t.Fatalf("got %d duplicate groups, want 1", len(report.Duplicates))
+	}
+	mileage := make(map[string]float64)
+	for _, order := range report.Orders["Jane Doe"] {
+		mileage[order.Filename] = order.Mileage
+	}
+	// Both copies share what's left of the cap after S41001's 10 miles
+	total := 0.0
+	for _, duplicate := range report.Duplicates[0].Orders {
+		if got, want := duplicate.Order.Mileage, mileage[duplicate.Order.Filename]; got != want {
+			t.Errorf("duplicate %s distance = %g, want %g", duplicate.Order.Filename, got, want)
+		}
+		total += duplicate.Order.Mileage
+	}
+	if total != 2 {
+		t.Errorf("duplicate distances add up to %g, want 2", total)
+	}
+}
@@ -0,0 +1,91 @@
+package fileutils
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+	"github.com/jlsnow301/cutsheet-traveller/utils"
+)
+
+type routeInfo struct {
+	Date           string
+	Origin         string
+	OrderIDs       []string
+	NaiveMileage   float64
+	ChainedMileage float64
+}
+
+// Chains each employee's round trips from the same origin on the same day into
+// a single origin -> stop1 -> stop2 -> ... -> origin route, in event time order.
+// One-way and drop-off + pickup orders are left as they are.
+func chainRoutes(employeeOrders map[string][]orderInfo) map[string][]routeInfo {
+	employeeRoutes := make(map[string][]routeInfo)
+
+	for employee, orders := range employeeOrders {
+		groups := make(map[string][]orderInfo)
+		for _, order := range orders {
+			if order.TripModel != travel.RoundTrip {
+				continue
+			}
+			key := order.Date + "|" + order.OriginAddress
+			groups[key] = append(groups[key], order)
+		}
+
+		for _, group := range groups {
+			if len(group) < 2 {
+				continue
+			}
+
+			sort.SliceStable(group, func(i, j int) bool {
+				return group[i].EventTime.Before(group[j].EventTime)
+			})
+
+			route, err := chainRoute(group)
+			if err != nil {
+				utils.PrintRed(fmt.Sprintf("Unable to chain %s's orders on %s: %v", employee, group[0].Date, err))
+				continue
+			}
+
+			employeeRoutes[employee] = append(employeeRoutes[employee], route)
+		}
+
+		// Groups come out of the map in any order, so sort on everything
+		// that tells routes apart
+		routes := employeeRoutes[employee]
+		sort.SliceStable(routes, func(i, j int) bool {
+			if routes[i].Date != routes[j].Date {
+				return routes[i].Date < routes[j].Date
+			}
+			if routes[i].Origin != routes[j].Origin {
+				return routes[i].Origin < routes[j].Origin
+			}
+			return routes[i].OrderIDs[0] < routes[j].OrderIDs[0]
+		})
+	}
+
+	return employeeRoutes
+}
+
+func chainRoute(orders []orderInfo) (routeInfo, error) {
+	first := orders[0]
+	route := routeInfo{Date: first.Date, Origin: first.Origin}
+
+	stops := []string{first.OriginAddress}
+	for _, order := range orders {
+		stops = append(stops, order.Destination)
+		route.OrderIDs = append(route.OrderIDs, order.OrderID)
+		route.NaiveMileage += order.Mileage
+	}
+	stops = append(stops, first.OriginAddress)
+
+	distance, err := travel.GetRouteDistance(stops, &first.EventTime)
+	if err != nil {
+		return routeInfo{}, err
+	}
+
+	route.NaiveMileage = travel.Round(route.NaiveMileage)
+	route.ChainedMileage = distance
+
+	return route, nil
+}
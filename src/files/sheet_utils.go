@@ -0,0 +1,242 @@
+package fileutils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+var (
+	currencyFormat = "$#,##0.00"
+	dateFormat     = "yyyy-mm-dd"
+)
+
+var tableNameRe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Writes an employee's orders as a filterable table with formula totals, so
+// corrections made in Excel carry through. Fills in which cells hold the
+// employee's totals for the Summary sheet.
+func writeEmployeeSheet(f *excelize.File, report Report, employee string, summary *employeeSummary, index int) error {
+	sheetName := summary.sheetName
+	orders := report.Orders[employee]
+
+	// Set employee name and color
+	f.SetCellValue(sheetName, "A1", employee)
+	bgColor := employeeColor(report.Config, employee)
+	textColor := getContrastColor(bgColor)
+	style, _ := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{bgColor}, Pattern: 1},
+		Font: &excelize.Font{Bold: true, Color: textColor},
+	})
+	f.SetCellStyle(sheetName, "A1", "A1", style)
+	if report.Period != nil {
+		f.SetCellValue(sheetName, "B1", report.Period.String())
+	}
+
+	// Set headers
+	distanceHeader := fmt.Sprintf("Distance (%s)", travel.OutputUnit().Label)
+	headers := []string{"Order ID", distanceHeader, "Trip", "Rate", "Reimbursement", "Date", "Origin", "Destination", "Equipment", "Adjustments"}
+	for _, column := range report.Columns {
+		headers = append(headers, column.Header)
+	}
+	setRow(f, sheetName, 2, headers)
+	lastColumn, _ := excelize.ColumnNumberToName(len(headers))
+
+	// Set header style
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	f.SetCellStyle(sheetName, "A2", lastColumn+"2", headerStyle)
+
+	distanceFormat := distanceNumberFormat(travel.OutputPrecision())
+	distanceStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &distanceFormat})
+	currencyStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &currencyFormat})
+	dateStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+
+	// Fill in order data
+	row := 3
+	for _, order := range orders {
+		var rate any = "No rate"
+		if perMile, ok := rateFor(report.Config, order.EventTime); ok {
+			rate = perMile
+		}
+
+		values := []any{
+			order.OrderID,
+			order.Mileage,
+			string(order.TripModel),
+			rate,
+			nil,
+			excelDate(order.Date),
+			order.Origin,
+			order.Destination,
+			equipmentNote(order),
+			strings.Join(order.Adjustments, "; "),
+		}
+		for _, column := range report.Columns {
+			values = append(values, column.value(order))
+		}
+		setRow(f, sheetName, row, values)
+		f.SetCellFormula(sheetName, fmt.Sprintf("E%d", row), reimbursementFormula(fmt.Sprintf("B%d", row), fmt.Sprintf("D%d", row)))
+		row++
+	}
+
+	lastRow := row - 1
+	f.SetCellStyle(sheetName, "B3", fmt.Sprintf("B%d", lastRow), distanceStyle)
+	f.SetCellStyle(sheetName, "D3", fmt.Sprintf("E%d", lastRow), currencyStyle)
+	f.SetCellStyle(sheetName, "F3", fmt.Sprintf("F%d", lastRow), dateStyle)
+
+	if err := f.AddTable(sheetName, &excelize.Table{
+		Range:          fmt.Sprintf("A2:%s%d", lastColumn, lastRow),
+		Name:           tableName(sheetName, index),
+		StyleName:      "TableStyleLight1",
+		ShowRowStripes: boolPtr(true),
+	}); err != nil {
+		return err
+	}
+
+	// Set total mileage with the same color scheme as the employee header
+	totalRow := row + 1
+	totalStyle, _ := f.NewStyle(&excelize.Style{
+		Fill:         excelize.Fill{Type: "pattern", Color: []string{bgColor}, Pattern: 1},
+		Font:         &excelize.Font{Bold: true, Color: textColor},
+		CustomNumFmt: &distanceFormat,
+	})
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", totalRow), "Total Mileage:")
+	f.SetCellFormula(sheetName, fmt.Sprintf("B%d", totalRow), fmt.Sprintf("SUM(B3:B%d)", lastRow))
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", totalRow), fmt.Sprintf("B%d", totalRow), totalStyle)
+
+	totalCurrencyStyle, _ := f.NewStyle(&excelize.Style{
+		Fill:         excelize.Fill{Type: "pattern", Color: []string{bgColor}, Pattern: 1},
+		Font:         &excelize.Font{Bold: true, Color: textColor},
+		CustomNumFmt: &currencyFormat,
+	})
+	f.SetCellValue(sheetName, fmt.Sprintf("D%d", totalRow), "Total Reimbursement:")
+	f.SetCellFormula(sheetName, fmt.Sprintf("E%d", totalRow), fmt.Sprintf("SUM(E3:E%d)", lastRow))
+	f.SetCellStyle(sheetName, fmt.Sprintf("D%d", totalRow), fmt.Sprintf("E%d", totalRow), totalCurrencyStyle)
+
+	summary.mileageCell = fmt.Sprintf("B%d", totalRow)
+	summary.reimbursementCell = fmt.Sprintf("E%d", totalRow)
+
+	if routes := report.Routes[employee]; len(routes) > 0 {
+		chainedRow := totalRow + 2
+		writeRoutes(f, sheetName, chainedRow, totalRow, routes, report, routeStyles{
+			total:         totalStyle,
+			totalCurrency: totalCurrencyStyle,
+			header:        headerStyle,
+			distance:      distanceStyle,
+			currency:      currencyStyle,
+			date:          dateStyle,
+		})
+
+		summary.mileageCell = fmt.Sprintf("B%d", chainedRow)
+		summary.reimbursementCell = fmt.Sprintf("E%d", chainedRow)
+	}
+
+	// Set column widths
+	f.SetColWidth(sheetName, "A", lastColumn, 15)
+
+	return nil
+}
+
+type routeStyles struct {
+	total         int
+	totalCurrency int
+	header        int
+	distance      int
+	currency      int
+	date          int
+}
+
+// Writes the chained totals at row and the daily routes they came from below.
+// The chained totals are the employee's totals less what chaining saved.
+func writeRoutes(f *excelize.File, sheetName string, row, totalRow int, routes []routeInfo, report Report, styles routeStyles) {
+	firstRoute := row + 3
+	lastRoute := firstRoute + len(routes) - 1
+
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Chained Mileage:")
+	f.SetCellFormula(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf(
+		"B%d-SUM(D%d:D%d)+SUM(E%d:E%d)", totalRow, firstRoute, lastRoute, firstRoute, lastRoute))
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), styles.total)
+
+	f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), "Chained Reimbursement:")
+	f.SetCellFormula(sheetName, fmt.Sprintf("E%d", row), fmt.Sprintf(
+		"E%d-ROUND(SUMPRODUCT((D%d:D%d-E%d:E%d)*F%d:F%d)*%s,2)",
+		totalRow, firstRoute, lastRoute, firstRoute, lastRoute, firstRoute, lastRoute, milesFactor()))
+	f.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("E%d", row), styles.totalCurrency)
+
+	setRow(f, sheetName, firstRoute-1, []string{"Date", "Origin", "Orders", "Naive", "Chained", "Rate"})
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", firstRoute-1), fmt.Sprintf("F%d", firstRoute-1), styles.header)
+
+	for i, route := range routes {
+		routeRow := firstRoute + i
+
+		// Routes without a rate save nothing in reimbursement
+		rate := 0.0
+		if date, err := time.Parse("2006-01-02", route.Date); err == nil {
+			rate, _ = rateFor(report.Config, date)
+		}
+
+		setRow(f, sheetName, routeRow, []any{
+			excelDate(route.Date),
+			route.Origin,
+			strings.Join(route.OrderIDs, " > "),
+			route.NaiveMileage,
+			route.ChainedMileage,
+			rate,
+		})
+	}
+
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", firstRoute), fmt.Sprintf("A%d", lastRoute), styles.date)
+	f.SetCellStyle(sheetName, fmt.Sprintf("D%d", firstRoute), fmt.Sprintf("E%d", lastRoute), styles.distance)
+	f.SetCellStyle(sheetName, fmt.Sprintf("F%d", firstRoute), fmt.Sprintf("F%d", lastRoute), styles.currency)
+}
+
+// Flags orders with enough equipment to justify a pickup trip, pointing out
+// the ones that aren't billed for one
+func equipmentNote(order orderInfo) string {
+	if !order.EquipmentHeavy {
+		return ""
+	}
+	if order.TripModel != travel.DropoffPickup {
+		return "Heavy, no pickup billed"
+	}
+	return "Heavy"
+}
+
+// Reimbursement for the distance cell at the per-mile rate cell, blank when
+// there's no rate
+func reimbursementFormula(distanceCell, rateCell string) string {
+	return fmt.Sprintf(`IF(ISNUMBER(%s),ROUND(%s*%s*%s,2),"")`, rateCell, distanceCell, milesFactor(), rateCell)
+}
+
+// Miles in one output unit, for converting distances before applying per-mile rates
+func milesFactor() string {
+	factor := travel.Miles.FromMeters(travel.OutputUnit().ToMeters(1))
+	return fmt.Sprintf("%g", factor)
+}
+
+// A YYYY-MM-DD date as a real date cell, or the text if it doesn't parse
+func excelDate(date string) any {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return parsed
+}
+
+// Table names must be unique in the workbook and can't contain spaces
+func tableName(sheetName string, index int) string {
+	name := tableNameRe.ReplaceAllString(sheetName, "_")
+	return fmt.Sprintf("Orders_%d_%s", index, name)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,97 @@
+package fileutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+const summarySheetName = "Summary"
+
+// employeeSummary is one employee's row on the Summary sheet.
+type employeeSummary struct {
+	employee string
+	// Empty when the employee has no orders and so no sheet
+	sheetName string
+	orders    int
+	errors    int
+	// Cells on the employee's sheet holding their totals, chained in chain mode
+	mileageCell       string
+	reimbursementCell string
+}
+
+// Writes a row per employee, linking to their sheet, and a grand total
+func writeSummary(f *excelize.File, cfg *config.Config, summaries map[string]*employeeSummary) {
+	employees := make([]string, 0, len(summaries))
+	for employee := range summaries {
+		employees = append(employees, employee)
+	}
+	employees = sortEmployees(cfg, employees)
+
+	f.SetCellValue(summarySheetName, "A1", "Summary")
+	titleStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 14},
+	})
+	f.SetCellStyle(summarySheetName, "A1", "A1", titleStyle)
+
+	distanceHeader := fmt.Sprintf("Distance (%s)", travel.OutputUnit().Label)
+	setRow(f, summarySheetName, 2, []string{"Employee", "Orders", distanceHeader, "Reimbursement", "Errors"})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	f.SetCellStyle(summarySheetName, "A2", "E2", headerStyle)
+
+	distanceFormat := distanceNumberFormat(travel.OutputPrecision())
+	distanceStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &distanceFormat})
+	currencyStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &currencyFormat})
+	linkStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "#0563C1", Underline: "single"},
+	})
+
+	row := 3
+	for _, employee := range employees {
+		summary := summaries[employee]
+		setRow(f, summarySheetName, row, []any{summary.employee, summary.orders, 0, 0, summary.errors})
+
+		// Link to the employee's totals so corrections on their sheet show up here
+		if summary.sheetName != "" {
+			sheetRef := fmt.Sprintf("'%s'!", strings.ReplaceAll(summary.sheetName, "'", "''"))
+			f.SetCellFormula(summarySheetName, fmt.Sprintf("C%d", row), sheetRef+summary.mileageCell)
+			f.SetCellFormula(summarySheetName, fmt.Sprintf("D%d", row), sheetRef+summary.reimbursementCell)
+
+			cell := fmt.Sprintf("A%d", row)
+			f.SetCellHyperLink(summarySheetName, cell, sheetRef+"A1", "Location")
+			f.SetCellStyle(summarySheetName, cell, cell, linkStyle)
+		}
+		row++
+	}
+
+	f.SetCellStyle(summarySheetName, "C3", fmt.Sprintf("C%d", row), distanceStyle)
+	f.SetCellStyle(summarySheetName, "D3", fmt.Sprintf("D%d", row), currencyStyle)
+
+	f.SetCellValue(summarySheetName, fmt.Sprintf("A%d", row), "Total")
+	for _, column := range []string{"B", "C", "D", "E"} {
+		f.SetCellFormula(summarySheetName, fmt.Sprintf("%s%d", column, row), fmt.Sprintf("SUM(%s3:%s%d)", column, column, row-1))
+	}
+	totalDistanceStyle, _ := f.NewStyle(&excelize.Style{
+		Font:         &excelize.Font{Bold: true},
+		CustomNumFmt: &distanceFormat,
+	})
+	totalCurrencyStyle, _ := f.NewStyle(&excelize.Style{
+		Font:         &excelize.Font{Bold: true},
+		CustomNumFmt: &currencyFormat,
+	})
+	totalStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	f.SetCellStyle(summarySheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), totalStyle)
+	f.SetCellStyle(summarySheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("C%d", row), totalDistanceStyle)
+	f.SetCellStyle(summarySheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("D%d", row), totalCurrencyStyle)
+	f.SetCellStyle(summarySheetName, fmt.Sprintf("E%d", row), fmt.Sprintf("E%d", row), totalStyle)
+
+	f.SetColWidth(summarySheetName, "A", "A", 25)
+	f.SetColWidth(summarySheetName, "B", "E", 15)
+}
@@ -0,0 +1,67 @@
+package fileutils
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jlsnow301/cutsheet-traveller/utils"
+)
+
+// Runs job for every index below count on up to workers goroutines and waits
+// for them all to finish.
+func runPool(count, workers int, job func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				job(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+}
+
+// progress keeps a live "Employee: 3/10" counter on the current line.
+type progress struct {
+	mu       sync.Mutex
+	employee string
+	done     int
+	total    int
+}
+
+func newProgress(employee string, total int) *progress {
+	p := &progress{employee: employee, total: total}
+	p.print()
+	return p
+}
+
+func (p *progress) increment() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.print()
+}
+
+func (p *progress) print() {
+	utils.PrintProgress(fmt.Sprintf("%s: %d/%d cut sheets", p.employee, p.done, p.total))
+}
+
+// Moves past the counter so the next output starts on a fresh line
+func (p *progress) finish() {
+	fmt.Println()
+}
@@ -9,7 +9,9 @@ require (
 	github.com/fatih/color v1.18.0
 	github.com/joho/godotenv v1.5.1
 	github.com/xuri/excelize/v2 v2.9.0
+	golang.org/x/time v0.11.0
 	googlemaps.github.io/maps v1.7.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -26,5 +28,4 @@ require (
 	golang.org/x/net v0.37.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
-	golang.org/x/time v0.11.0 // indirect
 )
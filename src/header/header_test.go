@@ -0,0 +1,183 @@
+package header
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
+	"github.com/jlsnow301/cutsheet-traveller/utils"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// The origins the fixtures are written against
+func testConfig() *config.Config {
+	return &config.Config{
+		Origins: []config.Origin{
+			{Name: "Fremont", Address: "Fremont kitchen"},
+			{Name: "Eastlake", Aliases: []string{"Lake Union Kitchen"}, Address: "Eastlake kitchen"},
+		},
+	}
+}
+
+// Each fixture in testdata/lines is the extracted text of an anonymized cut
+// sheet, one line per line, and each PDF in testdata/pdf a synthetic one. The
+// header parsed from it must match testdata/golden/<name>.json.
+func TestParseHeaderInfoGolden(t *testing.T) {
+	lineFixtures, err := filepath.Glob(filepath.Join("testdata", "lines", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdfFixtures, err := filepath.Glob(filepath.Join("testdata", "pdf", "*.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lineFixtures) == 0 || len(pdfFixtures) == 0 {
+		t.Fatal("no fixtures found in testdata")
+	}
+
+	for _, path := range lineFixtures {
+		name := strings.TrimSuffix(filepath.Base(path), ".txt")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+			headerText, _ := utils.SplitTexts(lines)
+			checkGolden(t, name, ParseHeaderInfo(headerText, testConfig()))
+		})
+	}
+
+	for _, path := range pdfFixtures {
+		name := "pdf_" + strings.TrimSuffix(filepath.Base(path), ".pdf")
+		t.Run(name, func(t *testing.T) {
+			lines, err := utils.ExtractTextFromPDF(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			headerText, _ := utils.SplitTexts(lines)
+			checkGolden(t, name, ParseHeaderInfo(headerText, testConfig()))
+		})
+	}
+}
+
+func checkGolden(t *testing.T, name string, info HeaderInfo) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	goldenPath := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("%v (run go test ./header -update to create it)", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("header for %s doesn't match %s\ngot:\n%s\nwant:\n%s", name, goldenPath, got, want)
+	}
+}
+
+func TestParseHeaderInfoWithTrace(t *testing.T) {
+	lines := []string{
+		"S21271",
+		"Fremont",
+		"Site Address: 1201 3rd Ave",
+		"Seattle WA 98101",
+		"Headcount: 40",
+		"Something unrecognized",
+	}
+
+	_, trace := ParseHeaderInfoWithTrace(lines, testConfig())
+
+	want := []TraceEntry{
+		{Line: 0, Text: "S21271", Matcher: "Order ID"},
+		{Line: 1, Text: "Fremont", Matcher: "Origin Fremont"},
+		{Line: 2, Text: "Site Address: 1201 3rd Ave", Matcher: "Site Address:"},
+		{Line: 3, Text: "Seattle WA 98101", Matcher: "Address continued"},
+		{Line: 4, Text: "Headcount: 40", Matcher: "Headcount:"},
+	}
+
+	if len(trace) != len(want) {
+		t.Fatalf("got %d trace entries, want %d: %+v", len(trace), len(want), trace)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Errorf("trace[%d] = %+v, want %+v", i, trace[i], want[i])
+		}
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"1201 3rd Ave, Seattle WA 98101", "1201 3rd Ave, Seattle WA 98101"},
+		{"500 Terry Ave N", "500 Terry Ave N, Seattle"},
+		{"1400 NorthlakeWay, Seattle98103Headcount: 60", "1400 Northlake Way, Seattle 98103"},
+		{"2200 Westlake Ave N, Seattle, ", "2200 Westlake Ave N, Seattle"},
+		{", Seattle WA  98109 ", "Seattle WA 98109"},
+		{"", ""},
+		{" , ", ""},
+	}
+
+	for _, test := range tests {
+		if got := normalizeAddress(test.address); got != test.want {
+			t.Errorf("normalizeAddress(%q) = %q, want %q", test.address, got, test.want)
+		}
+	}
+}
+
+func TestParseHeaderInfoOriginCase(t *testing.T) {
+	info := ParseHeaderInfo([]string{"S31240", "LAKE UNION KITCHEN"}, testConfig())
+	if info.Origin != "Eastlake" {
+		t.Errorf("origin = %q, want Eastlake", info.Origin)
+	}
+}
+
+func TestParseHeaderInfoOriginStreet(t *testing.T) {
+	info := ParseHeaderInfo([]string{
+		"S31240",
+		"Fremont",
+		"Site Address: 2101",
+		"Eastlake Ave E",
+		"Headcount: 20",
+		"Eastlake",
+	}, testConfig())
+
+	if info.Origin != "Fremont" {
+		t.Errorf("origin = %q, want Fremont", info.Origin)
+	}
+	if want := "2101, Eastlake Ave E, Seattle"; info.Destination != want {
+		t.Errorf("destination = %q, want %q", info.Destination, want)
+	}
+}
+
+func TestParseHeaderInfoNilConfig(t *testing.T) {
+	t.Setenv("FREMONT_ADDRESS", "Fremont kitchen")
+	t.Setenv("EASTLAKE_ADDRESS", "")
+
+	info := ParseHeaderInfo([]string{"S21271", "Fremont"}, nil)
+	if info.Origin != "Fremont" {
+		t.Errorf("origin = %q, want Fremont", info.Origin)
+	}
+}
@@ -4,16 +4,25 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/jlsnow301/cutsheet-traveller/config"
 )
 
 type HeaderInfo struct {
-	OrderID     string
-	Origin      string
-	Destination string
-	Size        string
-	EventTime   string
-	SuiteInfo   string
-	EventDate   time.Time
+	OrderID     string    `json:"order_id"`
+	Origin      string    `json:"origin"`
+	Destination string    `json:"destination"`
+	Size        string    `json:"size"`
+	EventTime   string    `json:"event_time"`
+	SuiteInfo   string    `json:"suite_info"`
+	EventDate   time.Time `json:"event_date"`
+	TripModel   string    `json:"trip_model"`
+	HasPickup   bool      `json:"has_pickup"`
+	Client      string    `json:"client"`
+	Contact     string    `json:"contact"`
+	Phone       string    `json:"phone"`
+	EndTime     string    `json:"end_time"`
+	ServiceType string    `json:"service_type"`
 }
 
 func hasDatePrefix(line string) bool {
@@ -63,15 +72,18 @@ func normalizeAddress(address string) string {
 		address = address[:headcountIndex]
 	}
 
-	// Trim any trailing commas and spaces
-	address = strings.TrimRight(address, ", ")
+	// Trim any commas and spaces around it, and runs of spaces inside
+	address = strings.Join(strings.Fields(strings.Trim(address, ", ")), " ")
+	if address == "" {
+		return ""
+	}
 
 	// Add a space before any capitalized letter unless one already exists
 	re := regexp.MustCompile(`([a-z])([A-Z])`)
 	address = re.ReplaceAllString(address, "$1 $2")
 
 	// Ensure there's a space before the ZIP code if it exists
-	zipRe := regexp.MustCompile(`(\D)(\d{5})$`)
+	zipRe := regexp.MustCompile(`([^\d\s])(\d{5})$`)
 	address = zipRe.ReplaceAllString(address, "$1 $2")
 
 	// Check if the address contains a ZIP code
@@ -82,20 +94,56 @@ func normalizeAddress(address string) string {
 
 	// If there's no ZIP code and no "Seattle", add "Seattle"
 	if !hasZip && !hasSeattle {
-		address += " Seattle"
+		address += ", Seattle"
 	}
 
 	return address
 }
 
-func ParseHeaderInfo(content []string) HeaderInfo {
+// TraceEntry records which matcher picked up a header line.
+type TraceEntry struct {
+	// Line is the index of the line in the header
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Matcher string `json:"matcher"`
+}
+
+// ParseHeaderInfo reads the header lines of a cut sheet. The origin is the
+// first line that is one of the names or aliases in the config, or
+// config.Default() when cfg is nil.
+func ParseHeaderInfo(content []string, cfg *config.Config) HeaderInfo {
+	info, _ := ParseHeaderInfoWithTrace(content, cfg)
+	return info
+}
+
+// ParseHeaderInfoWithTrace is ParseHeaderInfo, also returning which matcher
+// fired on which line. Lines nothing matched are left out.
+func ParseHeaderInfoWithTrace(content []string, cfg *config.Config) (HeaderInfo, []TraceEntry) {
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
 	info := HeaderInfo{}
+	var trace []TraceEntry
 	addressParts := []string{}
 
 	matchers := map[string]func(string){
-		"Fremont":     func(s string) { info.Origin = s },
-		"Eastlake":    func(s string) { info.Origin = s },
 		"Start Time:": func(s string) { info.EventTime = splitAfterColon(s) },
+		"Trip Type:":  func(s string) { info.TripModel = splitAfterColon(s) },
+		"End Time:":   func(s string) { info.EndTime = splitAfterColon(s) },
+		"Client:":     func(s string) { info.Client = splitAfterColon(s) },
+		"Customer:":   func(s string) { info.Client = splitAfterColon(s) },
+		// Delivery or full service
+		"Service Type:":    func(s string) { info.ServiceType = splitAfterColon(s) },
+		"On-Site Contact:": func(s string) { info.Contact = splitAfterColon(s) },
+		"Onsite Contact:":  func(s string) { info.Contact = splitAfterColon(s) },
+		"Contact:":         func(s string) { info.Contact = splitAfterColon(s) },
+		"Contact Phone:":   func(s string) { info.Phone = splitAfterColon(s) },
+		"On-Site Phone:":   func(s string) { info.Phone = splitAfterColon(s) },
+		"Phone:":           func(s string) { info.Phone = splitAfterColon(s) },
+		// A scheduled pickup means the driver has to come back for equipment
+		"Pickup Time:":  func(s string) { info.HasPickup = true },
+		"Pick Up Time:": func(s string) { info.HasPickup = true },
 		"Site Address:": func(s string) {
 			siteAddress := splitAfterColon(s)
 			addressParts = append(addressParts, siteAddress)
@@ -118,25 +166,46 @@ func ParseHeaderInfo(content []string) HeaderInfo {
 		},
 	}
 
-	for _, line := range content {
+	for i, line := range content {
 		line = strings.TrimSpace(line)
+		// The items start here, so the address can't continue past it
+		if line == "Food/Service Item" {
+			break
+		}
+
+		fired := func(matcher string) {
+			trace = append(trace, TraceEntry{Line: i, Text: line, Matcher: matcher})
+		}
 
 		if info.EventDate.IsZero() && hasDatePrefix(line) {
 			// Check for date in format "Day, MM/DD/YYYY"
 			if date, err := time.Parse("Monday, 1/2/2006", line); err == nil {
 				info.EventDate = date
+				fired("Event date")
 				continue
 			}
 		}
 
 		if info.OrderID == "" && hasOrderID(line) {
 			info.OrderID = line
+			fired("Order ID")
+		}
+
+		// Outside the address, so a street like "Eastlake Ave E" isn't taken
+		// for the kitchen
+		if info.Origin == "" && len(addressParts) == 0 {
+			if origin := cfg.FindOrigin(line); origin != nil {
+				info.Origin = origin.Name
+				fired("Origin " + origin.Name)
+				continue
+			}
 		}
 
 		matched := false
 		for prefix, handler := range matchers {
 			if strings.HasPrefix(line, prefix) {
 				handler(line)
+				fired(prefix)
 				matched = true
 				break
 			}
@@ -144,6 +213,7 @@ func ParseHeaderInfo(content []string) HeaderInfo {
 
 		if !matched && info.Destination == "" && len(addressParts) > 0 && line != "" {
 			addressParts = append(addressParts, line)
+			fired("Address continued")
 		}
 	}
 
@@ -152,5 +222,5 @@ func ParseHeaderInfo(content []string) HeaderInfo {
 		info.Destination = normalizeAddress(strings.Join(addressParts, ", "))
 	}
 
-	return info
+	return info, trace
 }
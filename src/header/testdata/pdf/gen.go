@@ -0,0 +1,70 @@
+//go:build ignore
+
+// Writes the synthetic cut sheets in this folder. Run with `go run gen.go`
+// from here after changing them.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jlsnow301/cutsheet-traveller/utils/fakepdf"
+)
+
+var sheets = map[string][]fakepdf.Text{
+	// Labels and values in separate text runs, with a second column of
+	// details beside the address
+	"two_column.pdf": {
+		{40, 750, "S21271"}, {300, 750, "Monday, 3/2/2026"},
+		{40, 730, "Fremont"}, {300, 730, "Client:"}, {360, 730, "Example Holdings LLC"},
+		{40, 710, "Site Address:"}, {130, 710, "1201 3rd Ave"}, {300, 710, "Contact:"}, {370, 710, "Pat Example"},
+		{40, 690, "Seattle WA 98101"}, {300, 690, "Contact Phone:"}, {400, 690, "206-555-0142"},
+		{40, 670, "Headcount: 40"}, {300, 670, "Start Time:"}, {390, 670, "11:30 AM"},
+		{40, 640, "Food/Service Item"}, {300, 640, "Qty"},
+		{40, 620, "40"}, {70, 620, "Box Lunch"},
+		{40, 600, "4"}, {70, 600, "Chafing Dish"},
+	},
+	// A label left empty beside a right column label and value in one run
+	"empty_label.pdf": {
+		{40, 750, "S21290"}, {300, 750, "Monday, 3/2/2026"},
+		{40, 730, "Fremont"}, {300, 730, "Client: Example Holdings LLC"},
+		{40, 710, "Site Name:"}, {300, 710, "Start Time: 11:30 AM"},
+		{40, 690, "Site Address:"}, {130, 690, "1201 3rd Ave"}, {300, 690, "Contact:"}, {370, 690, "Pat Example"},
+		{40, 670, "Seattle WA 98101"}, {300, 670, "End Time: 1:30 PM"},
+		{40, 650, "Headcount: 40"},
+		{40, 620, "Food/Service Item"},
+		{40, 600, "40"}, {70, 600, "Box Lunch"},
+	},
+	// One column, with each value in its own run after the label
+	"single_column.pdf": {
+		{40, 750, "S30990"},
+		{40, 730, "Friday, 5/8/2026"},
+		{40, 710, "Eastlake"},
+		{40, 690, "Site Address:"}, {150, 690, "400 Broad St"},
+		{40, 670, "Seattle WA 98109"},
+		{40, 650, "Headcount:"}, {150, 650, "120"},
+		{40, 630, "Start Time:"}, {150, 630, "5:00 PM"},
+		{40, 610, "Pickup Time:"}, {150, 610, "9:00 PM"},
+		{40, 580, "Food/Service Item"},
+		{40, 560, "120"}, {70, 560, "Dinner Buffet"},
+	},
+	// Text split into several runs within words, as some PDF writers do
+	"split_runs.pdf": {
+		{40, 750, "S3"}, {52, 750, "1007"},
+		{40, 730, "Tuesday,"}, {94, 730, "5/12/2026"},
+		{40, 710, "Fre"}, {58, 710, "mont"},
+		{40, 690, "Site Address: 500"}, {148, 690, "Terry Ave N"},
+		{40, 670, "Headcount: 12"},
+		{40, 650, "Start Time: 12:00"}, {148, 650, "PM"},
+		{40, 620, "Food/Service Item"},
+	},
+}
+
+func main() {
+	for name, texts := range sheets {
+		if err := os.WriteFile(name, fakepdf.Render(texts), 0644); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+}
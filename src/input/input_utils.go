@@ -29,7 +29,14 @@ func PromptUserForNumber(maxNumber int) int {
 	}
 }
 
-func PromptForEventTime() string {
+// PromptForEventTime explains what's wrong with a cut sheet's event time and
+// asks for it. Cut sheets are processed in parallel, so the whole prompt is
+// shown while holding the terminal.
+func PromptForEventTime(filename, problem string) string {
+	utils.LockPrompt()
+	defer utils.UnlockPrompt()
+
+	utils.PrintRed(fmt.Sprintf("%s: %s", filename, problem))
 	for {
 		fmt.Print("Please enter the event time (HH:MM AM/PM): ")
 		scanner := bufio.NewScanner(os.Stdin)
@@ -50,3 +57,8 @@ func PromptForEventTime() string {
 		}
 	}
 }
+
+// WaitForEnter blocks until the user presses Enter.
+func WaitForEnter() {
+	bufio.NewScanner(os.Stdin).Scan()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+
+	"github.com/jlsnow301/cutsheet-traveller/header"
+	"github.com/jlsnow301/cutsheet-traveller/utils"
+)
+
+// inspection is everything inspect found out about a cut sheet.
+type inspection struct {
+	File          string              `json:"file"`
+	Extractor     string              `json:"extractor"`
+	Lines         []string            `json:"lines"`
+	HeaderLines   []string            `json:"header_lines"`
+	RemainingText []string            `json:"remaining_lines"`
+	Trace         []header.TraceEntry `json:"trace"`
+	Header        header.HeaderInfo   `json:"header"`
+}
+
+// runInspect is the "inspect <file.pdf>" subcommand. It shows how a cut sheet
+// is read, for working out why one ended up on the Errors sheet.
+func runInspect(args []string) int {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Print the inspection as JSON, e.g. to attach to a bug report")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: inspect [--json] <file.pdf>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 1
+	}
+
+	// The .env is optional here, but needed for custom origins and the extractor
+	envPath := filepath.Join(filepath.Dir(os.Args[0]), ".env")
+	if _, err := os.Stat(envPath); err == nil {
+		if err := godotenv.Load(envPath); err != nil {
+			utils.PrintRed("Error loading .env file")
+			return 1
+		}
+	}
+
+	if err := utils.SetExtractorFromEnv(); err != nil {
+		utils.PrintRed(err.Error())
+		return 1
+	}
+
+	// Not validated, origin addresses aren't needed to read a cut sheet
+	cfg, err := loadConfig(envPath)
+	if err != nil {
+		utils.PrintRed(fmt.Sprintf("Error loading config: %v", err))
+		return 1
+	}
+
+	result := inspection{File: flags.Arg(0)}
+	result.Lines, result.Extractor, err = utils.ExtractTextFromPDFWithExtractor(result.File)
+	if err != nil {
+		utils.PrintRed(fmt.Sprintf("Error extracting text from PDF: %v", err))
+		return 1
+	}
+
+	result.HeaderLines, result.RemainingText = utils.SplitTexts(result.Lines)
+	result.Header, result.Trace = header.ParseHeaderInfoWithTrace(result.HeaderLines, cfg)
+
+	if *asJSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			utils.PrintRed(err.Error())
+			return 1
+		}
+		fmt.Println(string(data))
+		return 0
+	}
+
+	printInspection(result)
+	return 0
+}
+
+func printInspection(result inspection) {
+	utils.PrintHeader(result.File)
+
+	extractor := result.Extractor
+	if extractor != utils.Extractor() {
+		extractor += fmt.Sprintf(" (%s extraction failed or found nothing)", utils.Extractor())
+	}
+	utils.PrintStats(fmt.Sprintf("Extractor: %s", extractor))
+	fmt.Println()
+
+	utils.PrintYellow(fmt.Sprintf("Extracted lines (%d):", len(result.Lines)))
+	for i, line := range result.Lines {
+		fmt.Printf("%4d  %s\n", i, line)
+	}
+	fmt.Println()
+
+	utils.PrintYellow("Split:")
+	if len(result.HeaderLines) == 0 {
+		fmt.Println("Header: no header lines")
+	} else {
+		fmt.Printf("Header: lines 0-%d\n", len(result.HeaderLines)-1)
+	}
+	if len(result.RemainingText) == 0 {
+		fmt.Println("Food/Service section: not found")
+	} else if len(result.HeaderLines) == 0 {
+		fmt.Printf("Food/Service section: %d lines from line 0\n", len(result.RemainingText))
+	} else {
+		fmt.Printf("Food/Service section: %d lines after line %d\n", len(result.RemainingText), len(result.HeaderLines)-1)
+	}
+	fmt.Println()
+
+	utils.PrintYellow("Matchers:")
+	if len(result.Trace) == 0 {
+		fmt.Println("Nothing matched.")
+	}
+	for _, entry := range result.Trace {
+		fmt.Printf("%4d  %-20s %s\n", entry.Line, entry.Matcher, entry.Text)
+	}
+	fmt.Println()
+
+	utils.PrintYellow("Header:")
+	info := result.Header
+	eventDate := ""
+	if !info.EventDate.IsZero() {
+		eventDate = info.EventDate.Format("2006-01-02")
+	}
+	for _, field := range [][2]string{
+		{"Order ID", info.OrderID},
+		{"Event date", eventDate},
+		{"Start time", info.EventTime},
+		{"End time", info.EndTime},
+		{"Origin", info.Origin},
+		{"Destination", info.Destination},
+		{"Suite", info.SuiteInfo},
+		{"Headcount", info.Size},
+		{"Trip type", info.TripModel},
+		{"Pickup", fmt.Sprint(info.HasPickup)},
+		{"Service type", info.ServiceType},
+		{"Client", info.Client},
+		{"Contact", info.Contact},
+		{"Phone", info.Phone},
+	} {
+		value := field[1]
+		if value == "" {
+			value = "(none)"
+		}
+		fmt.Printf("%-14s %s\n", field[0]+":", value)
+	}
+}
@@ -0,0 +1,51 @@
+package items
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	lines := []string{
+		"Qty",
+		"Food/Service Item",
+		"40 Box Lunch",
+		"12:30 PM setup",
+		"2nd floor, freight elevator",
+		"10am drop",
+		"2 x Chafing Dish",
+		"3x Coffee Urn",
+		"1.5 Gal Lemonade",
+		"- no ice",
+		"Subtotal $400.00",
+		"1 Ignored",
+	}
+
+	want := []Item{
+		{Quantity: 40, Name: "Box Lunch", Notes: []string{"12:30 PM setup", "2nd floor, freight elevator", "10am drop"}},
+		{Quantity: 2, Name: "Chafing Dish"},
+		{Quantity: 3, Name: "Coffee Urn"},
+		{Quantity: 1.5, Name: "Gal Lemonade", Notes: []string{"no ice"}},
+	}
+
+	if got := Parse(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestIsEquipment(t *testing.T) {
+	tests := map[string]bool{
+		"Chafing Dish":    true,
+		"Tablecloth":      true,
+		"Vegetable Tray":  false,
+		"Coffee Urn":      true,
+		"Box Lunch":       false,
+		"Beverage Cooler": true,
+	}
+
+	for name, want := range tests {
+		if got := (Item{Name: name}).IsEquipment(); got != want {
+			t.Errorf("IsEquipment(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
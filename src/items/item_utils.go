@@ -0,0 +1,141 @@
+package items
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Item is one line of a cut sheet's Food/Service section.
+type Item struct {
+	Quantity float64
+	Name     string
+	Notes    []string
+}
+
+// Lines like "12 Box Lunch", "2 x Chafing Dish" or "1.5 Gal Coffee". The
+// quantity has to be followed by a space or an "x", so notes like "12:30 PM
+// setup" or "2nd floor" aren't items.
+var itemLineRe = regexp.MustCompile(`^(\d+(?:\.\d+)?)(?:\s*[xX])?\s+(\S.*)$`)
+
+// Column headings repeated at the top of each page
+var headingLines = map[string]bool{
+	"qty":               true,
+	"quantity":          true,
+	"item":              true,
+	"price":             true,
+	"food/service item": true,
+}
+
+// Lines that start the totals below the items
+var totalPrefixes = []string{"subtotal", "sub total", "total", "grand total", "tax", "service charge", "gratuity"}
+
+var (
+	equipmentKeywords = []string{
+		"chafer", "chafing", "cambro", "urn", "dispenser", "warmer", "cooler",
+		"table", "linen", "rack", "tent", "heater", "equipment", "rental",
+	}
+	equipmentThreshold = 5.0
+)
+
+// Parse turns the lines after "Food/Service Item" into items. A line starting
+// with a quantity starts an item; the lines after it are its notes.
+func Parse(lines []string) []Item {
+	var items []Item
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		if line == "" || headingLines[lower] {
+			continue
+		}
+		if isTotal(lower) {
+			break
+		}
+
+		if match := itemLineRe.FindStringSubmatch(line); match != nil {
+			quantity, _ := strconv.ParseFloat(match[1], 64)
+			items = append(items, Item{Quantity: quantity, Name: strings.TrimSpace(match[2])})
+			continue
+		}
+
+		// Anything before the first item is a stray heading
+		if len(items) > 0 {
+			last := &items[len(items)-1]
+			last.Notes = append(last.Notes, strings.TrimLeft(line, "-*• "))
+		}
+	}
+
+	return items
+}
+
+func isTotal(lower string) bool {
+	for _, prefix := range totalPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEquipment reports whether the item is something that has to be collected
+// after the event, like a chafing dish or a beverage urn.
+func (i Item) IsEquipment() bool {
+	// Keywords match the start of a word, so "table" catches "Tablecloth"
+	// but not "Vegetable Tray"
+	for _, word := range strings.FieldsFunc(strings.ToLower(i.Name), isSeparator) {
+		for _, keyword := range equipmentKeywords {
+			if strings.HasPrefix(word, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// EquipmentCount adds up the quantities of the equipment items.
+func EquipmentCount(items []Item) float64 {
+	count := 0.0
+	for _, item := range items {
+		if item.IsEquipment() {
+			count += item.Quantity
+		}
+	}
+	return count
+}
+
+// EquipmentHeavy reports whether an order has enough equipment to justify a
+// pickup trip.
+func EquipmentHeavy(items []Item) bool {
+	return EquipmentCount(items) >= equipmentThreshold
+}
+
+// SetEquipmentFromEnv reads EQUIPMENT_KEYWORDS and EQUIPMENT_THRESHOLD.
+func SetEquipmentFromEnv() error {
+	if keywordText := os.Getenv("EQUIPMENT_KEYWORDS"); keywordText != "" {
+		var keywords []string
+		for _, keyword := range strings.Split(keywordText, ",") {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+		}
+		equipmentKeywords = keywords
+	}
+
+	if thresholdText := os.Getenv("EQUIPMENT_THRESHOLD"); thresholdText != "" {
+		threshold, err := strconv.ParseFloat(thresholdText, 64)
+		if err != nil || threshold <= 0 {
+			return fmt.Errorf("invalid EQUIPMENT_THRESHOLD: %s", thresholdText)
+		}
+		equipmentThreshold = threshold
+	}
+
+	return nil
+}
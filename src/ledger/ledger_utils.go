@@ -0,0 +1,128 @@
+package ledger
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry records one order that went out in a report.
+type Entry struct {
+	RunID      string    `json:"run_id"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Employee   string    `json:"employee"`
+	OrderID    string    `json:"order_id"`
+	Filename   string    `json:"filename"`
+	FileHash   string    `json:"file_hash"`
+	Date       string    `json:"date"`
+	Mileage    float64   `json:"mileage"`
+	Unit       string    `json:"unit"`
+}
+
+// Ledger is an append-only JSON Lines file of every order already reimbursed,
+// so a cut sheet left in a folder isn't paid again next period.
+type Ledger struct {
+	path    string
+	mu      sync.Mutex
+	byOrder map[string]Entry
+	byHash  map[string]Entry
+}
+
+// Open loads the ledger at path. A missing file is an empty ledger.
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{
+		path:    path,
+		byOrder: make(map[string]Entry),
+		byHash:  make(map[string]Entry),
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		l.add(entry)
+	}
+
+	return l, scanner.Err()
+}
+
+// Paid returns the first time an order was reimbursed, matching either its
+// order ID or the hash of its cut sheet.
+func (l *Ledger) Paid(orderID, fileHash string) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.byOrder[orderID]; ok && orderID != "" {
+		return entry, true
+	}
+	if entry, ok := l.byHash[fileHash]; ok && fileHash != "" {
+		return entry, true
+	}
+	return Entry{}, false
+}
+
+// Record appends the entries to the ledger file.
+func (l *Ledger) Record(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+		l.add(entry)
+	}
+
+	return file.Close()
+}
+
+// Keeps the earliest entry for each order and file
+func (l *Ledger) add(entry Entry) {
+	if _, ok := l.byOrder[entry.OrderID]; !ok && entry.OrderID != "" {
+		l.byOrder[entry.OrderID] = entry
+	}
+	if _, ok := l.byHash[entry.FileHash]; !ok && entry.FileHash != "" {
+		l.byHash[entry.FileHash] = entry
+	}
+}
+
+// NewRunID identifies one report run, e.g. "20240315-093000-1a2b3c".
+func NewRunID(now time.Time) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
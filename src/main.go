@@ -4,15 +4,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 
+	"github.com/jlsnow301/cutsheet-traveller/config"
 	fileutils "github.com/jlsnow301/cutsheet-traveller/files"
 	"github.com/jlsnow301/cutsheet-traveller/input"
+	"github.com/jlsnow301/cutsheet-traveller/items"
+	"github.com/jlsnow301/cutsheet-traveller/ledger"
+	"github.com/jlsnow301/cutsheet-traveller/travel"
 	"github.com/jlsnow301/cutsheet-traveller/utils"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "inspect" {
+		os.Exit(runInspect(os.Args[2:]))
+	}
+
+	opts := parseOptions()
+
 	envPath := filepath.Join(filepath.Dir(os.Args[0]), ".env")
 	if _, err := os.Stat(envPath); os.IsNotExist(err) {
 		utils.PrintRed("Please create a .env file in the project root.")
@@ -25,13 +38,94 @@ func main() {
 		os.Exit(1)
 	}
 
-	cwd, err := os.Getwd()
+	if err := travel.SetOutputFromEnv(); err != nil {
+		utils.PrintRed(fmt.Sprintf("Error reading distance settings: %v", err))
+		os.Exit(1)
+	}
+
+	if err := utils.SetExtractorFromEnv(); err != nil {
+		utils.PrintRed(err.Error())
+		os.Exit(1)
+	}
+
+	if err := items.SetEquipmentFromEnv(); err != nil {
+		utils.PrintRed(fmt.Sprintf("Error reading equipment settings: %v", err))
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(envPath)
 	if err != nil {
-		fmt.Println("Error getting current directory:", err)
+		utils.PrintRed(fmt.Sprintf("Error loading config: %v", err))
+		os.Exit(1)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		utils.PrintRed(fmt.Sprintf("Invalid config: %v", err))
+		os.Exit(1)
+	}
+
+	startedAt := time.Now()
+	period, err := opts.reportPeriod(startedAt)
+	if err != nil {
+		utils.PrintRed(err.Error())
+		os.Exit(1)
+	}
+
+	exporters, err := opts.exporters()
+	if err != nil {
+		utils.PrintRed(err.Error())
 		os.Exit(1)
 	}
 
-	employeesDir := filepath.Join(cwd, "employees")
+	columnNames := opts.columns
+	if len(columnNames) == 0 && os.Getenv("REPORT_COLUMNS") != "" {
+		columnNames.Set(os.Getenv("REPORT_COLUMNS"))
+	}
+	columns, err := fileutils.ParseColumns(columnNames)
+	if err != nil {
+		utils.PrintRed(err.Error())
+		os.Exit(1)
+	}
+
+	provider, err := travel.NewProviderFromEnv()
+	if err != nil {
+		utils.PrintRed(fmt.Sprintf("Error setting up distance provider: %v", err))
+		os.Exit(1)
+	}
+
+	rateLimit := 10.0
+	if rateText := os.Getenv("ROUTER_RATE_LIMIT"); rateText != "" {
+		rateLimit, err = strconv.ParseFloat(rateText, 64)
+		if err != nil {
+			utils.PrintRed(fmt.Sprintf("Invalid ROUTER_RATE_LIMIT: %s", rateText))
+			os.Exit(1)
+		}
+	}
+	provider = travel.NewRateLimitedProvider(provider, rateLimit)
+
+	cache, err := newDistanceCache(provider, filepath.Dir(envPath), opts.refresh)
+	if err != nil {
+		utils.PrintRed(fmt.Sprintf("Error loading distance cache: %v", err))
+		os.Exit(1)
+	}
+	travel.SetProvider(cache)
+
+	ledgerPath := os.Getenv("LEDGER_FILE")
+	if ledgerPath == "" {
+		ledgerPath = filepath.Join(filepath.Dir(envPath), "ledger.jsonl")
+	}
+
+	paidLedger, err := ledger.Open(ledgerPath)
+	if err != nil {
+		utils.PrintRed(fmt.Sprintf("Error loading ledger: %v", err))
+		os.Exit(1)
+	}
+
+	employeesDir, err := filepath.Abs(opts.employeesDir)
+	if err != nil {
+		fmt.Println("Error getting current directory:", err)
+		os.Exit(1)
+	}
 
 	// Read the directory contents of the "employees" subfolder
 	files, err := os.ReadDir(employeesDir)
@@ -50,13 +144,114 @@ func main() {
 	}
 
 	utils.PrintHeader("Order Mileage")
+	if period != nil {
+		utils.PrintStats(fmt.Sprintf("Pay period: %s", period))
+		fmt.Println()
+	}
 
-	maxNumber := len(folders)
-	if maxNumber == 0 {
+	if len(folders) == 0 {
 		utils.PrintRed("No folders found in the 'employees' folder.")
 		os.Exit(1)
 	}
 
+	var foldersToSearch []string
+	switch {
+	case opts.all:
+		foldersToSearch = folders
+	case len(opts.employees) > 0:
+		foldersToSearch, err = matchFolders(folders, opts.employees)
+		if err != nil {
+			utils.PrintRed(err.Error())
+			os.Exit(1)
+		}
+	case opts.nonInteractive:
+		utils.PrintRed("Please pass --employee or --all when running non-interactively.")
+		os.Exit(1)
+	default:
+		foldersToSearch = promptForFolders(folders)
+	}
+
+	// Settings in the employees' own folders win over the roster
+	for _, folder := range foldersToSearch {
+		if err := cfg.LoadEmployeeFile(folder, filepath.Join(employeesDir, folder)); err != nil {
+			utils.PrintRed(fmt.Sprintf("Error loading employee settings: %v", err))
+			os.Exit(1)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		utils.PrintRed(fmt.Sprintf("Invalid employee settings: %v", err))
+		os.Exit(1)
+	}
+
+	// Check the reports can be written before spending time on the cut sheets
+	outputPath := opts.outputPath(period, foldersToSearch, startedAt)
+	for _, exporter := range exporters {
+		if err := fileutils.PrepareOutput(fileutils.ExportPath(outputPath, exporter), opts.force); err != nil {
+			utils.PrintRed(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	report := fileutils.CollectOrdersAndErrors(foldersToSearch, employeesDir, fileutils.CollectOptions{
+		Chain:             opts.chain,
+		NonInteractive:    opts.nonInteractive,
+		Period:            period,
+		Config:            cfg,
+		Workers:           opts.workers,
+		Ledger:            paidLedger,
+		IncludePaid:       opts.includePaid,
+		RunID:             ledger.NewRunID(startedAt),
+		ExcludeDuplicates: opts.excludeDuplicates,
+	})
+	report.Columns = columns
+
+	if err := cache.Save(); err != nil {
+		utils.PrintRed(fmt.Sprintf("Error saving distance cache: %v", err))
+	}
+
+	fmt.Println()
+	for _, exporter := range exporters {
+		exportPath := fileutils.ExportPath(outputPath, exporter)
+		if err := exportReport(exporter, report, exportPath, opts.nonInteractive); err != nil {
+			utils.PrintRed(fmt.Sprintf("Error creating %s: %v", exportPath, err))
+			os.Exit(1)
+		}
+		fmt.Printf("Created %s\n", exportPath)
+	}
+
+	// Only once the report is out are its orders considered paid
+	if !opts.dryRun {
+		if err := fileutils.RecordPaid(report, paidLedger, time.Now()); err != nil {
+			utils.PrintRed(fmt.Sprintf("Error updating ledger: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println()
+	utils.PrintStats(fmt.Sprintf("Run ID: %s", report.RunID))
+	if opts.dryRun {
+		utils.PrintYellow("Dry run: orders were not added to the ledger.")
+	}
+	if len(report.Paid) > 0 {
+		utils.PrintStats(fmt.Sprintf("Previously paid orders: %d", len(report.Paid)))
+	}
+	if len(report.Duplicates) > 0 {
+		utils.PrintStats(fmt.Sprintf("Duplicated orders: %d", len(report.Duplicates)))
+	}
+	utils.PrintStats(fmt.Sprintf("Distance cache hits: %d", cache.Hits))
+	utils.PrintStats(fmt.Sprintf("Distance cache misses: %d", cache.Misses))
+
+	// Let scripts know that some cut sheets need a look
+	if opts.nonInteractive && len(report.Errors) > 0 {
+		utils.PrintRed(fmt.Sprintf("%d cut sheets could not be processed.", len(report.Errors)))
+		os.Exit(2)
+	}
+}
+
+// promptForFolders asks which employee folder to use, or all of them.
+func promptForFolders(folders []string) []string {
+	maxNumber := len(folders)
+
 	fmt.Println("This tool searches for folders in the 'employees' folder.")
 	fmt.Println("It is stupid, it assumes all cut sheets are valid.")
 	fmt.Println("It will also skip cut sheets that it does not understand.")
@@ -80,21 +275,78 @@ func main() {
 	userNumber := input.PromptUserForNumber(maxNumber)
 
 	// Create an array with just the employee folder, or all folders if the user selected "All"
-	foldersToSearch := []string{}
-	if userNumber == maxNumber {
-		foldersToSearch = folders
-	} else {
-		foldersToSearch = append(foldersToSearch, folders[userNumber-1])
+	if len(folders) > 1 && userNumber == maxNumber {
+		return folders
 	}
+	return []string{folders[userNumber-1]}
+}
 
-	employeeOrders, errors := fileutils.CollectOrdersAndErrors(foldersToSearch, employeesDir)
+// matchFolders finds the employee folders named on the command line, ignoring case.
+func matchFolders(folders, names []string) ([]string, error) {
+	var matched []string
 
-	err = fileutils.CreateExcelFile(employeeOrders, errors)
-	if err != nil {
-		utils.PrintRed(fmt.Sprintf("Error creating Excel file: %v", err))
-		os.Exit(1)
+	for _, name := range names {
+		found := false
+		for _, folder := range folders {
+			if strings.EqualFold(folder, name) {
+				matched = append(matched, folder)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no employee folder named %q", name)
+		}
+	}
+
+	return matched, nil
+}
+
+// newDistanceCache wraps the provider in the on-disk distance cache.
+func newDistanceCache(provider travel.DistanceProvider, dir string, refresh bool) (*travel.CachedProvider, error) {
+	cachePath := os.Getenv("DISTANCE_CACHE_FILE")
+	if cachePath == "" {
+		cachePath = filepath.Join(dir, "distance_cache.json")
 	}
 
-	fmt.Println("\nExcel file created successfully.")
+	ttl := 30 * 24 * time.Hour
+	if ttlText := os.Getenv("DISTANCE_CACHE_TTL"); ttlText != "" {
+		parsed, err := time.ParseDuration(ttlText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DISTANCE_CACHE_TTL: %w", err)
+		}
+		ttl = parsed
+	}
+
+	return travel.NewCachedProvider(provider, cachePath, ttl, refresh)
+}
+
+// exportReport writes the report, asking to retry while the file is open in
+// another program such as Excel.
+func exportReport(exporter fileutils.Exporter, report fileutils.Report, path string, nonInteractive bool) error {
+	for {
+		err := exporter.Export(report, path)
+		if err == nil || !fileutils.IsLocked(err) {
+			return err
+		}
+
+		if nonInteractive {
+			return fmt.Errorf("the file is open in another program, close it and run again: %w", err)
+		}
+
+		utils.PrintYellow(fmt.Sprintf("%s is open in another program, probably Excel.", path))
+		fmt.Print("Close it and press Enter to try again: ")
+		input.WaitForEnter()
+	}
+}
+
+// loadConfig reads CONFIG_FILE, or config.yaml beside the .env.
+func loadConfig(envPath string) (*config.Config, error) {
+	configPath := os.Getenv("CONFIG_FILE")
+	if configPath == "" {
+		configPath = filepath.Join(filepath.Dir(envPath), "config.yaml")
+	}
 
+	return config.Load(configPath)
 }
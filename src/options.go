@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	fileutils "github.com/jlsnow301/cutsheet-traveller/files"
+	timeutils "github.com/jlsnow301/cutsheet-traveller/time"
+)
+
+type options struct {
+	refresh           bool
+	chain             bool
+	employees         stringList
+	all               bool
+	employeesDir      string
+	out               string
+	nonInteractive    bool
+	from              string
+	to                string
+	period            string
+	on                string
+	workers           int
+	formats           stringList
+	force             bool
+	includePaid       bool
+	dryRun            bool
+	excludeDuplicates bool
+	columns           stringList
+}
+
+// stringList collects a flag that may be repeated or comma separated.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*s = append(*s, part)
+		}
+	}
+	return nil
+}
+
+func parseOptions() options {
+	var opts options
+
+	flag.BoolVar(&opts.refresh, "refresh", false, "Ignore cached distances and fetch them again")
+	flag.BoolVar(&opts.chain, "chain", false, "Chain each employee's orders on the same day into one route")
+	flag.Var(&opts.employees, "employee", "Employee folder to report on (repeatable or comma separated)")
+	flag.BoolVar(&opts.all, "all", false, "Report on every employee folder")
+	flag.StringVar(&opts.employeesDir, "employees-dir", "employees", "Folder containing the employee folders")
+	flag.StringVar(&opts.out, "out", "", "Path of the report, may use {period}, {employee} and {timestamp} (default OUTPUT_TEMPLATE or "+fileutils.DefaultOutputTemplate+"); the extension follows --format")
+	flag.BoolVar(&opts.nonInteractive, "non-interactive", false, "Never prompt; record problems as errors instead")
+	flag.StringVar(&opts.from, "from", "", "Only include events on or after this date (YYYY-MM-DD)")
+	flag.StringVar(&opts.to, "to", "", "Only include events on or before this date (YYYY-MM-DD)")
+	flag.StringVar(&opts.period, "period", "", "Only include the last complete pay period: weekly, biweekly, semimonthly or monthly")
+	flag.StringVar(&opts.on, "on", "", "With --period, use the pay period containing this date (YYYY-MM-DD) instead")
+	flag.IntVar(&opts.workers, "workers", 4, "How many cut sheets to process at once")
+	flag.Var(&opts.formats, "format", "Report format: xlsx, csv, items or json (repeatable or comma separated, default xlsx)")
+	flag.BoolVar(&opts.force, "force", false, "Overwrite a report that already exists")
+	flag.BoolVar(&opts.includePaid, "include-paid", false, "Keep orders the ledger says were already reimbursed in the report")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "Write the report without adding its orders to the ledger")
+	flag.Var(&opts.columns, "columns", "Extra columns to show with each order: client, contact, phone, end-time, service or all")
+	flag.BoolVar(&opts.excludeDuplicates, "exclude-duplicates", false, "Count only the first copy of an order found more than once")
+	flag.Parse()
+
+	if len(opts.formats) == 0 {
+		opts.formats = stringList{"xlsx"}
+	}
+
+	return opts
+}
+
+// reportPeriod works out the date range to report on, or nil for everything.
+func (opts options) reportPeriod(today time.Time) (*timeutils.Period, error) {
+	if opts.period == "" {
+		if opts.from == "" && opts.to == "" {
+			return nil, nil
+		}
+
+		period, err := timeutils.NewPeriod(opts.from, opts.to, today)
+		if err != nil {
+			return nil, err
+		}
+		return &period, nil
+	}
+
+	if opts.from != "" || opts.to != "" {
+		return nil, errors.New("please use either --period or --from/--to, not both")
+	}
+
+	var anchor time.Time
+	if anchorText := os.Getenv("PAY_PERIOD_ANCHOR"); anchorText != "" {
+		parsed, err := timeutils.ParseDate(anchorText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PAY_PERIOD_ANCHOR: %w", err)
+		}
+		anchor = parsed
+	}
+
+	var period timeutils.Period
+	var err error
+	if opts.on != "" {
+		date, dateErr := timeutils.ParseDate(opts.on)
+		if dateErr != nil {
+			return nil, dateErr
+		}
+		period, err = timeutils.PayPeriod(opts.period, date, anchor)
+	} else {
+		period, err = timeutils.PreviousPayPeriod(opts.period, today, anchor)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &period, nil
+}
+
+// outputPath fills in --out, OUTPUT_TEMPLATE or the default template.
+func (opts options) outputPath(period *timeutils.Period, employees []string, now time.Time) string {
+	template := opts.out
+	if template == "" {
+		template = os.Getenv("OUTPUT_TEMPLATE")
+	}
+	if template == "" {
+		template = fileutils.DefaultOutputTemplate
+	}
+	return fileutils.OutputPath(template, period, employees, now)
+}
+
+// exporters are the writers for each --format, without repeats.
+func (opts options) exporters() ([]fileutils.Exporter, error) {
+	var exporters []fileutils.Exporter
+	seen := make(map[string]bool)
+
+	for _, format := range opts.formats {
+		exporter, err := fileutils.NewExporter(format)
+		if err != nil {
+			return nil, err
+		}
+		if seen[exporter.Extension()] {
+			continue
+		}
+		seen[exporter.Extension()] = true
+		exporters = append(exporters, exporter)
+	}
+
+	return exporters, nil
+}
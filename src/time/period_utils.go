@@ -0,0 +1,130 @@
+package timeutils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Period is an inclusive range of event dates.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether the date falls on or between the period's days.
+func (p Period) Contains(date time.Time) bool {
+	day := truncateDay(date)
+	return !day.Before(p.Start) && !day.After(p.End)
+}
+
+// String is the human readable form, e.g. "2024-03-01 to 2024-03-15".
+func (p Period) String() string {
+	return p.Start.Format(dateLayout) + " to " + p.End.Format(dateLayout)
+}
+
+// Slug is the form used in file names, e.g. "2024-03-01_2024-03-15".
+func (p Period) Slug() string {
+	return p.Start.Format(dateLayout) + "_" + p.End.Format(dateLayout)
+}
+
+// ParseDate parses a YYYY-MM-DD date.
+func ParseDate(text string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(text))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, please use YYYY-MM-DD", text)
+	}
+	return date, nil
+}
+
+// NewPeriod builds a period from YYYY-MM-DD dates. A missing end date means
+// up to and including today.
+func NewPeriod(from, to string, today time.Time) (Period, error) {
+	if from == "" {
+		return Period{}, fmt.Errorf("a start date is required")
+	}
+
+	start, err := ParseDate(from)
+	if err != nil {
+		return Period{}, err
+	}
+
+	period := Period{Start: start, End: truncateDay(today)}
+	if to != "" {
+		if period.End, err = ParseDate(to); err != nil {
+			return Period{}, err
+		}
+	}
+
+	if period.End.Before(period.Start) {
+		return Period{}, fmt.Errorf("%s is before %s", period.End.Format(dateLayout), from)
+	}
+
+	return period, nil
+}
+
+// PayPeriod returns the pay period of the preset that contains the date.
+// Weekly and bi-weekly periods start on the anchor's weekday, and bi-weekly
+// periods line up with the anchor itself. The anchor defaults to a Monday.
+//
+// Presets are "weekly", "biweekly", "semimonthly" and "monthly".
+func PayPeriod(preset string, date, anchor time.Time) (Period, error) {
+	date = truncateDay(date)
+	if anchor.IsZero() {
+		anchor = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	anchor = truncateDay(anchor)
+
+	switch normalizePreset(preset) {
+	case "weekly":
+		return periodFromAnchor(date, anchor, 7), nil
+	case "biweekly":
+		return periodFromAnchor(date, anchor, 14), nil
+	case "semimonthly":
+		if date.Day() <= 15 {
+			start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+			return Period{Start: start, End: start.AddDate(0, 0, 14)}, nil
+		}
+		start := time.Date(date.Year(), date.Month(), 16, 0, 0, 0, 0, time.UTC)
+		return Period{Start: start, End: lastOfMonth(date)}, nil
+	case "monthly":
+		start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return Period{Start: start, End: lastOfMonth(date)}, nil
+	default:
+		return Period{}, fmt.Errorf("unknown pay period: %s", preset)
+	}
+}
+
+// PreviousPayPeriod returns the last pay period that ended before the date.
+func PreviousPayPeriod(preset string, date, anchor time.Time) (Period, error) {
+	current, err := PayPeriod(preset, date, anchor)
+	if err != nil {
+		return Period{}, err
+	}
+	return PayPeriod(preset, current.Start.AddDate(0, 0, -1), anchor)
+}
+
+func periodFromAnchor(date, anchor time.Time, days int) Period {
+	offset := int(date.Sub(anchor).Hours()/24) % days
+	if offset < 0 {
+		offset += days
+	}
+
+	start := date.AddDate(0, 0, -offset)
+	return Period{Start: start, End: start.AddDate(0, 0, days-1)}
+}
+
+func normalizePreset(preset string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(preset))
+}
+
+func lastOfMonth(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Cut sheet dates are parsed without a zone, so compare them as UTC days
+func truncateDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
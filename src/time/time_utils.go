@@ -1,18 +1,23 @@
 package timeutils
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/jlsnow301/cutsheet-traveller/input"
 )
 
-func GetEventTime(eventDate time.Time, eventTime string) (*time.Time, error) {
+// GetEventTime merges the cut sheet's start time into its date. Missing or
+// invalid times are prompted for, naming the cut sheet, unless interactive is
+// false.
+func GetEventTime(eventDate time.Time, eventTime, filename string, interactive bool) (*time.Time, error) {
 	if eventTime == "" {
-		color.Red("No event time provided.")
-		eventTime = input.PromptForEventTime()
+		if !interactive {
+			return nil, errors.New("no event time provided")
+		}
+		eventTime = input.PromptForEventTime(filename, "No event time provided.")
 	}
 
 	// Convert eventTime to uppercase to handle lowercase am/pm
@@ -20,9 +25,11 @@ func GetEventTime(eventDate time.Time, eventTime string) (*time.Time, error) {
 
 	// Try parsing with both "03:04 PM" and "3:04 PM" formats
 	parsedTime, err := parseTimeWithFormats(eventTime)
+	if err != nil && !interactive {
+		return nil, fmt.Errorf("invalid event time: %s", eventTime)
+	}
 	if err != nil {
-		color.Red(fmt.Sprintf("Invalid event time: %s. Please re-enter.", eventTime))
-		eventTime = input.PromptForEventTime()
+		eventTime = input.PromptForEventTime(filename, fmt.Sprintf("Invalid event time: %s. Please re-enter.", eventTime))
 		// Ensure the re-entered time is also converted to uppercase
 		eventTime = strings.ToUpper(eventTime)
 		parsedTime, err = parseTimeWithFormats(eventTime)
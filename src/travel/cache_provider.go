@@ -0,0 +1,135 @@
+package travel
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// CachedProvider remembers distances from another provider in a JSON file
+// so re-running a report for the same venues doesn't hit the API again.
+type CachedProvider struct {
+	provider DistanceProvider
+	path     string
+	ttl      time.Duration
+	// Entries fetched before this are ignored, set by refresh
+	notBefore time.Time
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	dirty     bool
+
+	Hits   int
+	Misses int
+}
+
+type cacheEntry struct {
+	Meters    float64   `json:"meters"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// NewCachedProvider loads the cache at path. Entries older than ttl are
+// ignored, as is everything fetched before this run when refresh is set. A
+// ttl of zero never expires.
+func NewCachedProvider(provider DistanceProvider, path string, ttl time.Duration, refresh bool) (*CachedProvider, error) {
+	c := &CachedProvider{
+		provider: provider,
+		path:     path,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+	}
+	if refresh {
+		c.notBefore = time.Now()
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *CachedProvider) Distance(origin, destination string, departure *time.Time) (float64, error) {
+	return c.lookup(routeKey(origin, destination), func() (float64, error) {
+		return c.provider.Distance(origin, destination, departure)
+	})
+}
+
+func (c *CachedProvider) RouteDistance(stops []string, departure *time.Time) (float64, error) {
+	if len(stops) == 2 {
+		return c.Distance(stops[0], stops[1], departure)
+	}
+
+	// Without multi-stop support the legs are cached one at a time
+	if _, ok := c.provider.(RouteProvider); !ok {
+		return routeDistance(legsOnly{c}, stops, departure)
+	}
+
+	return c.lookup(routeKey(stops...), func() (float64, error) {
+		return routeDistance(c.provider, stops, departure)
+	})
+}
+
+func (c *CachedProvider) lookup(key string, fetch func() (float64, error)) (float64, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && !c.expired(entry) {
+		c.count(&c.Hits)
+		return entry.Meters, nil
+	}
+
+	// Fetched without the lock so lookups can run in parallel
+	c.count(&c.Misses)
+	meters, err := fetch()
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{Meters: meters, FetchedAt: time.Now()}
+	c.dirty = true
+	c.mu.Unlock()
+
+	return meters, nil
+}
+
+func (c *CachedProvider) count(counter *int) {
+	c.mu.Lock()
+	*counter++
+	c.mu.Unlock()
+}
+
+func (c *CachedProvider) expired(entry cacheEntry) bool {
+	if entry.FetchedAt.Before(c.notBefore) {
+		return true
+	}
+	return c.ttl > 0 && time.Since(entry.FetchedAt) > c.ttl
+}
+
+// Save writes the cache back to disk if anything changed.
+func (c *CachedProvider) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0644)
+}
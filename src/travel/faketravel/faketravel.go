@@ -0,0 +1,188 @@
+// Package faketravel is an in-process stand-in for the Google Maps Directions
+// API, for testing the pipeline without a network or an API key. Distances
+// and failures are scripted per origin and destination.
+package faketravel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jlsnow301/cutsheet-traveller/travel"
+)
+
+const directionsPath = "/maps/api/directions/json"
+
+// Server answers Directions requests with the scripted legs.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	legs     map[leg]result
+	requests []Request
+}
+
+// Request is a Directions request the server received.
+type Request struct {
+	Origin      string
+	Destination string
+	Waypoints   []string
+	// Departure is the departure_time sent, "now" or a Unix time
+	Departure string
+}
+
+type leg struct {
+	origin      string
+	destination string
+}
+
+// A scripted answer for one leg: a distance, or a status other than OK
+type result struct {
+	meters int
+	status string
+}
+
+// NewServer starts a server with nothing scripted, closed when the test ends.
+// Legs that aren't scripted get a NOT_FOUND status, as Google gives for an
+// address it can't find.
+func NewServer(t testing.TB) *Server {
+	s := &Server{legs: make(map[leg]result)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveDirections))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Install makes the server the distance provider for the rest of the test,
+// through the Google provider pointed at it.
+func (s *Server) Install(t testing.TB) {
+	t.Setenv("DISTANCE_PROVIDER", "google")
+	t.Setenv("GOOGLE_MAPS_API_KEY", "fake-key")
+	t.Setenv("GOOGLE_MAPS_BASE_URL", s.URL)
+
+	provider, err := travel.NewProviderFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	travel.SetProvider(provider)
+	t.Cleanup(func() { travel.SetProvider(nil) })
+}
+
+// SetDistance scripts the driving distance from origin to destination, one
+// way. The way back has to be scripted separately.
+func (s *Server) SetDistance(origin, destination string, meters int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.legs[leg{origin, destination}] = result{meters: meters, status: "OK"}
+}
+
+// SetStatus scripts a failure for directions from origin to destination, such
+// as "ZERO_RESULTS" or "OVER_QUERY_LIMIT".
+func (s *Server) SetStatus(origin, destination, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.legs[leg{origin, destination}] = result{status: status}
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// The parts of the Directions response the Google provider reads
+type directionsResponse struct {
+	Status       string          `json:"status"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	Routes       []routeResponse `json:"routes"`
+}
+
+type routeResponse struct {
+	Summary string        `json:"summary"`
+	Legs    []legResponse `json:"legs"`
+}
+
+type legResponse struct {
+	Distance     valueResponse `json:"distance"`
+	Duration     valueResponse `json:"duration"`
+	StartAddress string        `json:"start_address"`
+	EndAddress   string        `json:"end_address"`
+}
+
+type valueResponse struct {
+	Text  string `json:"text"`
+	Value int    `json:"value"`
+}
+
+func (s *Server) serveDirections(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != directionsPath {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	request := Request{
+		Origin:      query.Get("origin"),
+		Destination: query.Get("destination"),
+		Departure:   query.Get("departure_time"),
+	}
+	if waypoints := query.Get("waypoints"); waypoints != "" {
+		for _, waypoint := range strings.Split(waypoints, "|") {
+			if waypoint == "optimize:true" {
+				continue
+			}
+			request.Waypoints = append(request.Waypoints, strings.TrimPrefix(waypoint, "via:"))
+		}
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	response := s.directions(request, query.Get("key"))
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Builds the answer for a request from the scripted legs. The first leg that
+// isn't OK decides the status of the whole route.
+func (s *Server) directions(request Request, key string) directionsResponse {
+	if key == "" {
+		return directionsResponse{Status: "REQUEST_DENIED", ErrorMessage: "You must use an API key to authenticate each request."}
+	}
+
+	stops := append([]string{request.Origin}, request.Waypoints...)
+	stops = append(stops, request.Destination)
+
+	route := routeResponse{Summary: "Fake route"}
+	for i := 1; i < len(stops); i++ {
+		scripted, ok := s.legs[leg{stops[i-1], stops[i]}]
+		if !ok {
+			return directionsResponse{
+				Status:       "NOT_FOUND",
+				ErrorMessage: fmt.Sprintf("no distance scripted from %q to %q", stops[i-1], stops[i]),
+			}
+		}
+		if scripted.status != "OK" {
+			return directionsResponse{Status: scripted.status}
+		}
+
+		// Driving at 30 mph
+		seconds := scripted.meters * 3600 / 48280
+		route.Legs = append(route.Legs, legResponse{
+			Distance:     valueResponse{Text: fmt.Sprintf("%.1f km", float64(scripted.meters)/1000), Value: scripted.meters},
+			Duration:     valueResponse{Text: fmt.Sprintf("%d mins", seconds/60), Value: seconds},
+			StartAddress: stops[i-1],
+			EndAddress:   stops[i],
+		})
+	}
+
+	return directionsResponse{Status: "OK", Routes: []routeResponse{route}}
+}
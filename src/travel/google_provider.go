@@ -0,0 +1,84 @@
+package travel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"googlemaps.github.io/maps"
+)
+
+// googleProvider fetches distances from the Google Maps Directions API.
+type googleProvider struct {
+	client *maps.Client
+}
+
+// A base URL sends requests somewhere other than Google, like a proxy or a
+// fake server in tests.
+func newGoogleProvider(apiKey, baseURL string) (*googleProvider, error) {
+	options := []maps.ClientOption{maps.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		options = append(options, maps.WithBaseURL(strings.TrimRight(baseURL, "/")))
+	}
+
+	client, err := maps.NewClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Google Maps client: %w", err)
+	}
+
+	return &googleProvider{client: client}, nil
+}
+
+func (g *googleProvider) Distance(origin, destination string, departure *time.Time) (float64, error) {
+	return g.RouteDistance([]string{origin, destination}, departure)
+}
+
+func (g *googleProvider) RouteDistance(stops []string, departure *time.Time) (float64, error) {
+	directionsResult, err := g.getDirections(stops, departure)
+	if err != nil {
+		return 0, err
+	}
+
+	if directionsResult == nil || len(directionsResult.Legs) == 0 {
+		return 0, errors.New("no directions found")
+	}
+
+	total := 0
+	for _, leg := range directionsResult.Legs {
+		total += leg.Distance.Meters
+	}
+
+	return float64(total), nil
+}
+
+// getDirections fetches directions using Google Maps API. Stops between the
+// first and last are sent as waypoints.
+func (g *googleProvider) getDirections(stops []string, event *time.Time) (*maps.Route, error) {
+	request := &maps.DirectionsRequest{
+		Origin:        stops[0],
+		Destination:   stops[len(stops)-1],
+		Waypoints:     stops[1 : len(stops)-1],
+		DepartureTime: departureTime(event),
+	}
+
+	routes, _, err := g.client.Directions(context.Background(), request)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching directions: %w", err)
+	}
+
+	if len(routes) > 0 {
+		return &routes[0], nil
+	}
+	return nil, nil
+}
+
+// If the time is in the past, just say "now"
+func departureTime(event *time.Time) string {
+	if event == nil || event.Before(time.Now()) {
+		return "now"
+	}
+	return strconv.FormatInt(event.Unix(), 10)
+}
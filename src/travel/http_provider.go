@@ -0,0 +1,192 @@
+package travel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpProvider talks to a self-hosted OSRM or Valhalla router. Addresses are
+// turned into coordinates with a Nominatim-compatible geocoder unless they
+// are already written as "lat,lon".
+type httpProvider struct {
+	flavor      string
+	routerURL   string
+	geocoderURL string
+	client      *http.Client
+}
+
+type coordinates struct {
+	Lat float64
+	Lon float64
+}
+
+var coordinatesRe = regexp.MustCompile(`^\s*(-?\d+(?:\.\d+)?)\s*,\s*(-?\d+(?:\.\d+)?)\s*$`)
+
+func newHTTPProvider(flavor, routerURL, geocoderURL string) (*httpProvider, error) {
+	if routerURL == "" {
+		return nil, fmt.Errorf("ROUTER_URL is required for the %s provider", flavor)
+	}
+
+	return &httpProvider{
+		flavor:      flavor,
+		routerURL:   strings.TrimRight(routerURL, "/"),
+		geocoderURL: strings.TrimRight(geocoderURL, "/"),
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (h *httpProvider) Distance(origin, destination string, departure *time.Time) (float64, error) {
+	return h.RouteDistance([]string{origin, destination}, departure)
+}
+
+func (h *httpProvider) RouteDistance(stops []string, _ *time.Time) (float64, error) {
+	points := make([]coordinates, 0, len(stops))
+	for _, stop := range stops {
+		point, err := h.geocode(stop)
+		if err != nil {
+			return 0, err
+		}
+		points = append(points, point)
+	}
+
+	if h.flavor == "valhalla" {
+		return h.valhallaRoute(points)
+	}
+	return h.osrmRoute(points)
+}
+
+// geocode resolves an address to coordinates.
+func (h *httpProvider) geocode(address string) (coordinates, error) {
+	if match := coordinatesRe.FindStringSubmatch(address); match != nil {
+		lat, _ := strconv.ParseFloat(match[1], 64)
+		lon, _ := strconv.ParseFloat(match[2], 64)
+		return coordinates{Lat: lat, Lon: lon}, nil
+	}
+
+	if h.geocoderURL == "" {
+		return coordinates{}, fmt.Errorf("GEOCODER_URL is required to look up %q", address)
+	}
+
+	query := url.Values{}
+	query.Set("q", address)
+	query.Set("format", "json")
+	query.Set("limit", "1")
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := h.getJSON(h.geocoderURL+"/search?"+query.Encode(), &results); err != nil {
+		return coordinates{}, fmt.Errorf("error geocoding %q: %w", address, err)
+	}
+	if len(results) == 0 {
+		return coordinates{}, fmt.Errorf("no coordinates found for %q", address)
+	}
+
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return coordinates{}, err
+	}
+	lon, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return coordinates{}, err
+	}
+
+	return coordinates{Lat: lat, Lon: lon}, nil
+}
+
+func (h *httpProvider) osrmRoute(points []coordinates) (float64, error) {
+	pairs := make([]string, 0, len(points))
+	for _, point := range points {
+		pairs = append(pairs, fmt.Sprintf("%f,%f", point.Lon, point.Lat))
+	}
+	routeURL := fmt.Sprintf("%s/route/v1/driving/%s?overview=false", h.routerURL, strings.Join(pairs, ";"))
+
+	var response struct {
+		Code   string `json:"code"`
+		Routes []struct {
+			Distance float64 `json:"distance"`
+		} `json:"routes"`
+	}
+	if err := h.getJSON(routeURL, &response); err != nil {
+		return 0, fmt.Errorf("error fetching directions: %w", err)
+	}
+	if response.Code != "Ok" || len(response.Routes) == 0 {
+		return 0, fmt.Errorf("no directions found: %s", response.Code)
+	}
+
+	return response.Routes[0].Distance, nil
+}
+
+func (h *httpProvider) valhallaRoute(points []coordinates) (float64, error) {
+	locations := make([]map[string]float64, 0, len(points))
+	for _, point := range points {
+		locations = append(locations, map[string]float64{"lat": point.Lat, "lon": point.Lon})
+	}
+
+	request := map[string]any{
+		"locations": locations,
+		"costing":   "auto",
+		"units":     "kilometers",
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := h.client.Post(h.routerURL+"/route", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error fetching directions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error fetching directions: %s", resp.Status)
+	}
+
+	var response struct {
+		Trip struct {
+			Summary struct {
+				Length float64 `json:"length"`
+			} `json:"summary"`
+		} `json:"trip"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, err
+	}
+	if response.Trip.Summary.Length <= 0 {
+		return 0, errors.New("no directions found")
+	}
+
+	return response.Trip.Summary.Length * 1000, nil
+}
+
+func (h *httpProvider) getJSON(requestURL string, target any) error {
+	request, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	// Nominatim rejects requests without a user agent
+	request.Header.Set("User-Agent", "cutsheet-traveller")
+
+	resp, err := h.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,47 @@
+package travel
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitedProvider keeps parallel workers from flooding the provider.
+type rateLimitedProvider struct {
+	provider DistanceProvider
+	limiter  *rate.Limiter
+}
+
+// NewRateLimitedProvider allows at most requestsPerSecond calls to the
+// provider. A limit of zero or less leaves it unlimited.
+func NewRateLimitedProvider(provider DistanceProvider, requestsPerSecond float64) DistanceProvider {
+	if requestsPerSecond <= 0 {
+		return provider
+	}
+
+	return &rateLimitedProvider{
+		provider: provider,
+		limiter:  rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+	}
+}
+
+func (r *rateLimitedProvider) Distance(origin, destination string, departure *time.Time) (float64, error) {
+	if err := r.limiter.Wait(context.Background()); err != nil {
+		return 0, err
+	}
+	return r.provider.Distance(origin, destination, departure)
+}
+
+func (r *rateLimitedProvider) RouteDistance(stops []string, departure *time.Time) (float64, error) {
+	routeProvider, ok := r.provider.(RouteProvider)
+	if !ok {
+		// Each leg is its own request
+		return routeDistance(legsOnly{r}, stops, departure)
+	}
+
+	if err := r.limiter.Wait(context.Background()); err != nil {
+		return 0, err
+	}
+	return routeProvider.RouteDistance(stops, departure)
+}
@@ -0,0 +1,87 @@
+package travel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// staticProvider looks distances up in a CSV table of
+// "origin,destination,miles" rows. Useful offline and in tests.
+type staticProvider struct {
+	// Meters keyed by routeKey
+	distances map[string]float64
+}
+
+func newStaticProvider(path string) (*staticProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("STATIC_DISTANCES_FILE is required for the static provider")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	distances := make(map[string]float64)
+	for _, record := range records {
+		miles, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			// Most likely a header row
+			continue
+		}
+		distances[routeKey(record[0], record[1])] = Miles.ToMeters(miles)
+	}
+
+	return &staticProvider{distances: distances}, nil
+}
+
+// NewStaticProvider builds a provider from an in-memory table of miles keyed
+// by origin and destination.
+func NewStaticProvider(distances map[[2]string]float64) DistanceProvider {
+	p := &staticProvider{distances: make(map[string]float64)}
+	for route, miles := range distances {
+		p.distances[routeKey(route[0], route[1])] = Miles.ToMeters(miles)
+	}
+	return p
+}
+
+func (s *staticProvider) Distance(origin, destination string, _ *time.Time) (float64, error) {
+	if meters, ok := s.distances[routeKey(origin, destination)]; ok {
+		return meters, nil
+	}
+
+	// Driving distance is close enough to symmetric for our purposes
+	if meters, ok := s.distances[routeKey(destination, origin)]; ok {
+		return meters, nil
+	}
+
+	return 0, fmt.Errorf("no static distance from %q to %q", origin, destination)
+}
+
+// routeKey normalizes the stops of a route for lookups.
+func routeKey(stops ...string) string {
+	keys := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		keys = append(keys, normalizeKey(stop))
+	}
+	return strings.Join(keys, "|")
+}
+
+func normalizeKey(address string) string {
+	return strings.Join(strings.Fields(strings.ToLower(address)), " ")
+}
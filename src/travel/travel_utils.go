@@ -1,95 +1,137 @@
 package travel
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
+)
+
+// DistanceProvider calculates the driving distance between two addresses.
+type DistanceProvider interface {
+	// Distance returns the one-way driving distance in meters.
+	Distance(origin, destination string, departure *time.Time) (float64, error)
+}
+
+// RouteProvider can measure a route through several stops in one request.
+// Providers without it have the route measured one leg at a time.
+type RouteProvider interface {
+	// RouteDistance returns the driving distance in meters from the first
+	// stop through each following stop in order.
+	RouteDistance(stops []string, departure *time.Time) (float64, error)
+}
 
-	"github.com/fatih/color"
-	"googlemaps.github.io/maps"
+var (
+	providerMu sync.Mutex
+	provider   DistanceProvider
 )
 
-// getDirections fetches directions using Google Maps API.
-func getDirections(origin, destination string, event *time.Time) *maps.Route {
-	client, err := maps.NewClient(maps.WithAPIKey(os.Getenv("GOOGLE_MAPS_API_KEY")))
-	if err != nil {
-		color.Red(fmt.Sprintf("Error creating Google Maps client: %v", err))
-		return nil
-	}
+// SetProvider sets the provider used by GetBaseTravelDistance.
+func SetProvider(p DistanceProvider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
 
-	// If the time is in the past, just say "now"
-	var eventTime string
-	if event.Before(time.Now()) {
-		eventTime = "now"
-	} else {
-		eventTime = strconv.FormatInt(event.Unix(), 10)
-	}
+	provider = p
+}
 
-	request := &maps.DirectionsRequest{
-		Origin:        origin,
-		Destination:   destination,
-		DepartureTime: eventTime,
+// NewProviderFromEnv builds the provider named by DISTANCE_PROVIDER.
+// Google Maps is used when nothing is set.
+func NewProviderFromEnv() (DistanceProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("DISTANCE_PROVIDER")))
+
+	switch name {
+	case "", "google":
+		return newGoogleProvider(os.Getenv("GOOGLE_MAPS_API_KEY"), os.Getenv("GOOGLE_MAPS_BASE_URL"))
+	case "osrm", "valhalla":
+		return newHTTPProvider(name, os.Getenv("ROUTER_URL"), os.Getenv("GEOCODER_URL"))
+	case "static":
+		return newStaticProvider(os.Getenv("STATIC_DISTANCES_FILE"))
+	default:
+		return nil, fmt.Errorf("unknown distance provider: %s", name)
 	}
+}
 
-	routes, _, err := client.Directions(context.Background(), request)
+// GetBaseTravelDistance gets the distance covered by the trip model between
+// the origin and destination in the output unit, rounded to the output precision.
+func GetBaseTravelDistance(origin, destination string, event *time.Time, model TripModel) (float64, error) {
+	p, err := currentProvider()
 	if err != nil {
-		color.Red(fmt.Sprintf("Error fetching directions: %v", err))
-		return nil
+		return 0, err
 	}
 
-	if len(routes) > 0 {
-		return &routes[0]
+	meters, err := p.Distance(origin, destination, event)
+	if err != nil {
+		return 0, err
 	}
-	return nil
-}
-
-func getDistanceText(directionsResult *maps.Route) string {
-	if directionsResult == nil {
-		color.Red("No directions found.")
-		return ""
+	if meters <= 0 {
+		return 0, errors.New("no distance found")
 	}
 
-	leg := directionsResult.Legs[0]
-	distanceText := leg.Distance.HumanReadable
+	tripMeters := meters * float64(model.Legs())
 
-	return distanceText
+	return Round(outputUnit.FromMeters(tripMeters)), nil
 }
 
-func getDoubleDistance(distanceText string) (float64, error) {
-	// Split the miles off the end of the string
-	splitText := strings.SplitN(distanceText, " ", 2)
-	if len(splitText) < 2 {
-		return 0, errors.New("no distance found")
+// GetRouteDistance gets the distance of a route through the stops in order,
+// in the output unit, rounded to the output precision.
+func GetRouteDistance(stops []string, departure *time.Time) (float64, error) {
+	p, err := currentProvider()
+	if err != nil {
+		return 0, err
 	}
 
-	distance, err := strconv.ParseFloat(splitText[0], 64)
+	meters, err := routeDistance(p, stops, departure)
 	if err != nil {
 		return 0, err
 	}
 
-	double := 2.0
-	// Multiply by 2 for round trips
-	distance = distance * double
+	return Round(outputUnit.FromMeters(meters)), nil
+}
+
+func currentProvider() (DistanceProvider, error) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	if provider == nil {
+		p, err := NewProviderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	}
 
-	return distance, nil
+	return provider, nil
 }
 
-// GetBaseTravelTime gets the base travel time based on the origin and destination.
-func GetBaseTravelDistance(origin, destination string, event *time.Time) (float64, error) {
-	directionsResult := getDirections(origin, destination, event)
-	distanceText := getDistanceText(directionsResult)
-	if distanceText == "" {
-		return 0, errors.New("no directions found")
+func routeDistance(p DistanceProvider, stops []string, departure *time.Time) (float64, error) {
+	if len(stops) < 2 {
+		return 0, errors.New("a route needs at least two stops")
 	}
 
-	roundTripMiles, err := getDoubleDistance(distanceText)
-	if err != nil {
-		return 0, err
+	if routeProvider, ok := p.(RouteProvider); ok {
+		return routeProvider.RouteDistance(stops, departure)
+	}
+
+	total := 0.0
+	for i := 1; i < len(stops); i++ {
+		meters, err := p.Distance(stops[i-1], stops[i], departure)
+		if err != nil {
+			return 0, err
+		}
+		total += meters
 	}
 
-	return roundTripMiles, nil
+	return total, nil
+}
+
+// legsOnly hides a wrapper's RouteDistance so routes are measured leg by leg
+// through its Distance.
+type legsOnly struct {
+	provider DistanceProvider
+}
+
+func (l legsOnly) Distance(origin, destination string, departure *time.Time) (float64, error) {
+	return l.provider.Distance(origin, destination, departure)
 }
@@ -0,0 +1,65 @@
+package travel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TripModel describes how many times a driver covers the route for an order.
+type TripModel string
+
+const (
+	// OneWay is a handoff to another vehicle at the venue.
+	OneWay TripModel = "One-way"
+	// RoundTrip is a delivery and return to the origin.
+	RoundTrip TripModel = "Round trip"
+	// DropoffPickup is a round trip to deliver plus another to pick up equipment.
+	DropoffPickup TripModel = "Drop-off + pickup"
+)
+
+var nonLetterRe = regexp.MustCompile(`[^a-z]+`)
+
+// ParseTripModel understands the common ways of writing each trip model,
+// e.g. "one way", "round-trip", "dropoff/pickup".
+func ParseTripModel(text string) (TripModel, error) {
+	normalized := nonLetterRe.ReplaceAllString(strings.ToLower(text), "")
+
+	switch normalized {
+	case "oneway", "handoff", "single":
+		return OneWay, nil
+	case "roundtrip", "round", "delivery", "return":
+		return RoundTrip, nil
+	case "dropoffpickup", "dropoffandpickup", "deliveryandpickup", "deliverypickup", "pickup":
+		return DropoffPickup, nil
+	default:
+		return "", fmt.Errorf("unknown trip model: %s", text)
+	}
+}
+
+// Legs returns how many one-way legs the trip model covers.
+func (m TripModel) Legs() int {
+	switch m {
+	case OneWay:
+		return 1
+	case DropoffPickup:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// ServiceTripModel picks the trip model for a cut sheet's service type. Full
+// service events leave equipment behind to be picked up; deliveries don't.
+func ServiceTripModel(serviceType string) (TripModel, bool) {
+	normalized := nonLetterRe.ReplaceAllString(strings.ToLower(serviceType), "")
+
+	switch {
+	case strings.Contains(normalized, "fullservice"), strings.Contains(normalized, "staffed"):
+		return DropoffPickup, true
+	case strings.Contains(normalized, "delivery"), strings.Contains(normalized, "dropoff"):
+		return RoundTrip, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,89 @@
+package travel
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Unit is a distance unit used in the report.
+type Unit struct {
+	Name   string
+	Label  string
+	meters float64
+}
+
+var (
+	Miles      = Unit{Name: "miles", Label: "mi", meters: 1609.344}
+	Kilometers = Unit{Name: "kilometers", Label: "km", meters: 1000}
+)
+
+var (
+	outputUnit      = Miles
+	outputPrecision = 1
+)
+
+// ParseUnit accepts "miles"/"mi" or "kilometers"/"km".
+func ParseUnit(name string) (Unit, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "mi", "mile", "miles":
+		return Miles, nil
+	case "km", "kilometer", "kilometers", "kilometre", "kilometres":
+		return Kilometers, nil
+	default:
+		return Unit{}, fmt.Errorf("unknown distance unit: %s", name)
+	}
+}
+
+// FromMeters converts meters into this unit.
+func (u Unit) FromMeters(meters float64) float64 {
+	return meters / u.meters
+}
+
+// ToMeters converts a distance in this unit into meters.
+func (u Unit) ToMeters(distance float64) float64 {
+	return distance * u.meters
+}
+
+// SetOutput sets the unit and number of decimal places distances are reported in.
+func SetOutput(unit Unit, precision int) {
+	outputUnit = unit
+	outputPrecision = precision
+}
+
+// SetOutputFromEnv reads DISTANCE_UNIT and DISTANCE_PRECISION.
+func SetOutputFromEnv() error {
+	unit, err := ParseUnit(os.Getenv("DISTANCE_UNIT"))
+	if err != nil {
+		return err
+	}
+
+	precision := 1
+	if precisionText := os.Getenv("DISTANCE_PRECISION"); precisionText != "" {
+		precision, err = strconv.Atoi(precisionText)
+		if err != nil || precision < 0 {
+			return fmt.Errorf("invalid DISTANCE_PRECISION: %s", precisionText)
+		}
+	}
+
+	SetOutput(unit, precision)
+	return nil
+}
+
+// OutputUnit returns the unit distances are reported in.
+func OutputUnit() Unit {
+	return outputUnit
+}
+
+// OutputPrecision returns the number of decimal places distances are rounded to.
+func OutputPrecision() int {
+	return outputPrecision
+}
+
+// Round rounds a distance to the output precision.
+func Round(distance float64) float64 {
+	scale := math.Pow(10, float64(outputPrecision))
+	return math.Round(distance*scale) / scale
+}
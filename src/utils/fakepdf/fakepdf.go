@@ -0,0 +1,62 @@
+// Package fakepdf writes small synthetic cut sheets for tests.
+package fakepdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Text is a piece of text placed at X, Y in points from the bottom left of
+// the page.
+type Text struct {
+	X, Y float64
+	S    string
+}
+
+// Render writes a one page PDF in 10pt Courier, so every character is 6pt
+// wide.
+func Render(texts []Text) []byte {
+	var content bytes.Buffer
+	content.WriteString("BT\n/F1 10 Tf\n")
+	for _, t := range texts {
+		escaped := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(t.S)
+		fmt.Fprintf(&content, "1 0 0 1 %g %g Tm (%s) Tj\n", t.X, t.Y, escaped)
+	}
+	content.WriteString("ET")
+
+	widths := strings.TrimSpace(strings.Repeat("600 ", 95))
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding /FirstChar 32 /LastChar 126 /Widths [" + widths + "] >>",
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+// Lines renders lines of text down the left of the page, 20pt apart.
+func Lines(lines ...string) []byte {
+	texts := make([]Text, len(lines))
+	for i, line := range lines {
+		texts[i] = Text{X: 40, Y: 750 - 20*float64(i), S: line}
+	}
+	return Render(texts)
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dslipak/pdf"
+)
+
+// A run of words on one visual line, with no wide gap inside it
+type segment struct {
+	x    float64
+	end  float64
+	text string
+}
+
+type visualRow struct {
+	y        float64
+	glyphs   []pdf.Text
+	segments []segment
+}
+
+// A segment that starts with its own label, like "Start Time: 11:30 AM"
+var labelRe = regexp.MustCompile(`^[^:\s][^:]*:(\s|$)`)
+
+// Extracts the lines of the PDF from where each glyph sits on the page.
+// Glyphs at the same height make up a row, wide gaps split a row into
+// segments, and a label ending in ":" is kept with the value beside it. When
+// the header is laid out in two columns, the right column follows the left.
+func getLayoutLines(file *pdf.Reader) (lines []string, err error) {
+	// The pdf library panics on content it can't interpret
+	defer func() {
+		if r := recover(); r != nil {
+			lines, err = nil, fmt.Errorf("layout extraction failed: %v", r)
+		}
+	}()
+
+	inItems := false
+	for pageIndex := 0; pageIndex < file.NumPage(); pageIndex++ {
+		page := file.Page(pageIndex + 1)
+		if page.V.IsNull() {
+			continue
+		}
+
+		rows := groupRows(page.Content().Text)
+		var pageLines []string
+		pageLines, inItems = layoutPage(rows, inItems)
+		lines = append(lines, pageLines...)
+	}
+
+	return lines, nil
+}
+
+// Groups glyphs into rows from the top of the page down
+func groupRows(texts []pdf.Text) []*visualRow {
+	sorted := make([]pdf.Text, 0, len(texts))
+	for _, text := range texts {
+		if text.S != "" {
+			sorted = append(sorted, text)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Y > sorted[j].Y
+	})
+
+	var rows []*visualRow
+	for _, text := range sorted {
+		tolerance := math.Max(text.FontSize, 1) * 0.3
+		if len(rows) > 0 && math.Abs(rows[len(rows)-1].y-text.Y) <= tolerance {
+			row := rows[len(rows)-1]
+			row.glyphs = append(row.glyphs, text)
+			continue
+		}
+		rows = append(rows, &visualRow{y: text.Y, glyphs: []pdf.Text{text}})
+	}
+
+	for _, row := range rows {
+		sort.SliceStable(row.glyphs, func(i, j int) bool {
+			return row.glyphs[i].X < row.glyphs[j].X
+		})
+		row.segments = splitSegments(row.glyphs)
+	}
+
+	return rows
+}
+
+// Joins glyphs into words and words into segments, starting a new segment at
+// any gap wider than a couple of characters
+func splitSegments(glyphs []pdf.Text) []segment {
+	var segments []segment
+	var current *segment
+
+	for _, glyph := range glyphs {
+		size := math.Max(glyph.FontSize, 1)
+
+		if current != nil {
+			gap := glyph.X - current.end
+			switch {
+			case gap > size*1.5:
+				segments = append(segments, *current)
+				current = nil
+			case gap > size*0.15 && !strings.HasSuffix(current.text, " ") && glyph.S != " ":
+				current.text += " "
+			}
+		}
+
+		if current == nil {
+			current = &segment{x: glyph.X}
+		}
+		current.text += glyph.S
+		current.end = math.Max(current.end, glyph.X+glyph.W)
+	}
+	if current != nil {
+		segments = append(segments, *current)
+	}
+
+	var trimmed []segment
+	for _, s := range segments {
+		if s.text = strings.Join(strings.Fields(s.text), " "); s.text != "" {
+			trimmed = append(trimmed, s)
+		}
+	}
+	return trimmed
+}
+
+// Keeps "Label:" together with the value printed to its right. A label with
+// no value is left alone rather than taking the next label, or a segment from
+// across the column at columnX.
+func mergeLabels(segments []segment, columnX float64, hasColumns bool) []segment {
+	var merged []segment
+	for i := 0; i < len(segments); i++ {
+		s := segments[i]
+		if strings.HasSuffix(s.text, ":") && i+1 < len(segments) && !labelRe.MatchString(segments[i+1].text) &&
+			!(hasColumns && s.x < columnX && segments[i+1].x >= columnX) {
+			s.text += " " + segments[i+1].text
+			s.end = segments[i+1].end
+			i++
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// Lays out one page. Above the Food/Service section, segments in a right-hand
+// column are moved after the left column; in the section each row is one line
+// so quantities stay with their items.
+func layoutPage(rows []*visualRow, inItems bool) ([]string, bool) {
+	var lines, rightColumn []string
+
+	columnX, hasColumns := findColumn(rows)
+
+	for _, row := range rows {
+		segments := mergeLabels(row.segments, columnX, hasColumns)
+
+		if inItems {
+			texts := make([]string, len(segments))
+			for i, s := range segments {
+				texts[i] = s.text
+			}
+			lines = append(lines, strings.Join(texts, " "))
+			continue
+		}
+
+		isItemsHeading := false
+		for _, s := range segments {
+			if s.text == "Food/Service Item" {
+				isItemsHeading = true
+			}
+		}
+
+		if isItemsHeading {
+			// The header is finished, including its right column
+			lines = append(lines, rightColumn...)
+			rightColumn = nil
+			for _, s := range segments {
+				lines = append(lines, s.text)
+			}
+			inItems = true
+			continue
+		}
+
+		for _, s := range segments {
+			if hasColumns && s.x >= columnX {
+				rightColumn = append(rightColumn, s.text)
+			} else {
+				lines = append(lines, s.text)
+			}
+		}
+	}
+
+	return append(lines, rightColumn...), inItems
+}
+
+// Finds where a right-hand column starts: an X that several rows have a
+// second segment at, above the Food/Service section
+func findColumn(rows []*visualRow) (float64, bool) {
+	const bucketWidth = 10.0
+	counts := make(map[int]int)
+
+	for _, row := range rows {
+		// Labels are kept with their values so they count as one segment
+		segments := mergeLabels(row.segments, 0, false)
+		if len(segments) > 0 && segments[0].text == "Food/Service Item" {
+			break
+		}
+		for _, s := range segments[min(1, len(segments)):] {
+			counts[int(s.x/bucketWidth)]++
+		}
+	}
+
+	best, bestCount := 0, 0
+	for bucket, count := range counts {
+		if count > bestCount || (count == bestCount && bucket < best) {
+			best, bestCount = bucket, count
+		}
+	}
+
+	if bestCount < 3 {
+		return 0, false
+	}
+	// Allow for segments starting a little left of the bucket
+	return float64(best)*bucketWidth - bucketWidth, true
+}
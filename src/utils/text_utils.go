@@ -2,12 +2,41 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 
 	"github.com/dslipak/pdf"
 	"github.com/fatih/color"
 )
 
+const (
+	// LayoutExtractor rebuilds lines from where the text sits on the page
+	LayoutExtractor = "layout"
+	// FlatExtractor takes the text in the order the PDF lists it
+	FlatExtractor = "flat"
+)
+
+var extractor = LayoutExtractor
+
+// SetExtractorFromEnv reads PDF_EXTRACTOR: "layout" (the default) or "flat".
+func SetExtractorFromEnv() error {
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv("PDF_EXTRACTOR"))); name {
+	case "", LayoutExtractor:
+		extractor = LayoutExtractor
+	case FlatExtractor:
+		extractor = FlatExtractor
+	default:
+		return fmt.Errorf("unknown PDF_EXTRACTOR: %s", name)
+	}
+	return nil
+}
+
+// Extractor returns the extractor PDF_EXTRACTOR asked for.
+func Extractor() string {
+	return extractor
+}
+
 // Extracts all lines from the PDF file
 func getAllLines(file *pdf.Reader) ([]string, error) {
 	var allLines []string
@@ -61,23 +90,39 @@ func getProcessedLines(allLines []string) ([]string, error) {
 	return processedLines, nil
 }
 
+// ExtractTextFromPDF returns the lines of text in a cut sheet. The layout
+// extractor is used unless PDF_EXTRACTOR is "flat", and the flat one is the
+// fallback when it fails or finds nothing.
 func ExtractTextFromPDF(pdfPath string) ([]string, error) {
+	lines, _, err := ExtractTextFromPDFWithExtractor(pdfPath)
+	return lines, err
+}
+
+// ExtractTextFromPDFWithExtractor is ExtractTextFromPDF, also returning which
+// extractor produced the lines.
+func ExtractTextFromPDFWithExtractor(pdfPath string) ([]string, string, error) {
 	file, err := pdf.Open(pdfPath)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	if extractor == LayoutExtractor {
+		if lines, err := getLayoutLines(file); err == nil && len(lines) > 0 {
+			return lines, LayoutExtractor, nil
+		}
 	}
 
 	allLines, err := getAllLines(file)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	processedLines, err := getProcessedLines(allLines)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return processedLines, nil
+	return processedLines, FlatExtractor, nil
 }
 
 // splitTexts splits the text into header and food service items.
@@ -145,3 +190,31 @@ func PrintHeader(text string) {
 	PrintYellow("*")
 	fmt.Println()
 }
+
+// Held while the user is prompted, so progress from other cut sheets
+// doesn't write over the prompt
+var promptMu sync.Mutex
+
+// printProgress overwrites the current line with a cyan status. It prints
+// nothing while a prompt is shown; the next update catches up.
+func PrintProgress(text string) {
+	if !promptMu.TryLock() {
+		return
+	}
+	defer promptMu.Unlock()
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("\r\033[K%s", cyan(text))
+}
+
+// LockPrompt holds the terminal for one prompt at a time, starting it on a
+// fresh line below any progress. Release it with UnlockPrompt.
+func LockPrompt() {
+	promptMu.Lock()
+	fmt.Println()
+}
+
+// UnlockPrompt lets progress and other prompts use the terminal again.
+func UnlockPrompt() {
+	promptMu.Unlock()
+}